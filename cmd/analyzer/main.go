@@ -1,123 +1,601 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"math"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // База часовых поясов для -tz на системах без неё.
 
 	"github.com/es-debug/backend-academy-2024-go-template/internal/application"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/analyzer"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/detector"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/exporter"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/filter"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/finder"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/loader"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/alb"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/apache"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/cloudfront"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/gcp"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/jsonl"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/quantile"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/timespec"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/infrastructure/filer"
+	"golang.org/x/term"
 )
 
 const (
-	defaultPath    = "-"
-	defaultFrom    = "-"
-	defaultTo      = "-"
-	defaultFormat  = "markdown"
-	defaultField   = "-"
-	defaultValue   = "-"
-	defaultHighest = 3
-	defaultRead    = math.MaxInt
-	pathUsage      = "path to the log files"
-	fromUsage      = "the minimum time that must be exceeded by the time the log is recorded for analysis. " +
-		"The value must match the format \"2006-01-02T15:04:05 Z07:00\"."
-	toUsage = "the maximum time that must exceed the time of recording the log in order for it to be analyzed. " +
-		"The value must match the format \"2006-01-02T15:04:05 Z07:00\"."
-	formatUsage = "output format (available formats: markdown, adoc)"
-	fieldUsage  = "Filter by nginx log field (available filters: remote_add, remote_user, time_local, " +
-		"method, resource, protocol, status, body_bytes_sent, http_referer, http_user_agent). " +
+	defaultFrom       = "-"
+	defaultTo         = "-"
+	defaultFormat     = "markdown"
+	defaultFilter     = "-"
+	defaultField      = "-"
+	defaultValue      = "-"
+	defaultHighest    = 3
+	defaultRead       = math.MaxInt
+	defaultLogFormat  = parser.CombinedFormat
+	defaultInput      = "nginx"
+	defaultFields     = ""
+	defaultSample     = 10
+	defaultOnError    = analyzer.OnErrorFail
+	defaultQuarantine = "quarantine.txt"
+	defaultAccuracy   = quantile.DefaultAccuracy
+	defaultExact      = false
+	defaultParse      = 1
+	defaultExport     = "-"
+	defaultColumns    = ""
+	defaultTZ         = "Local"
+	defaultBucket     = "-"
+	defaultOutput     = filer.DefaultOutput
+	defaultColor      = colorAuto
+	defaultBaseDir    = ""
+	defaultExt        = ""
+	pathUsage         = "path to the log files: a local pattern (** matches any number of directories) or a url, may be repeated"
+	fromUsage         = "the minimum time that must be exceeded by the time the log is recorded for analysis. " + timeUsage
+	toUsage           = "the maximum time that must exceed the time of recording the log in order for it to be analyzed. " +
+		timeUsage
+	timeUsage = "The value may be now, an offset from now (-24h, -7d, now-1w), today, yesterday or tomorrow " +
+		"with an optional time of day (\"yesterday 00:00\"), RFC 3339 time (2006-01-02T15:04:05Z07:00), " +
+		"nginx time (02/Jan/2006:15:04:05 -0700), time without a zone (2006-01-02 15:04) or a date (2006-01-02). " +
+		"Times without a zone are interpreted in the -tz time zone"
+	tzUsage = "IANA time zone (e.g. Europe/Moscow, UTC) in which times without a zone are interpreted " +
+		"and the time bounds are written to the report"
+	bucketUsage = "duration of the time buckets by which the number of requests, status classes, bytes and latency " +
+		"are reported (e.g. 1m, 5m, 1h, 1d). Buckets are aligned to midnight in the -tz time zone"
+	formatUsage = "output format (available formats: markdown, adoc, html, json, csv, csv-zip, tsv, tsv-zip, openmetrics, " +
+		"text). text renders aligned tables for a terminal. " +
+		"The json report follows a versioned JSON Schema and contains all values unless -highest is specified. " +
+		"csv and tsv write a file per report table into the report directory, csv-zip and tsv-zip into report.zip. " +
+		"openmetrics atomically writes report.prom for the node_exporter textfile collector"
+	outputUsage = "where to write the report: - for stdout (e.g. -format text -output -), a file or a directory " +
+		"(a path ending with a separator or an existing directory) to which the report is written with its default " +
+		"name, e.g. report.md. The path may contain the placeholders {format}, {from}, {to} (the time bounds or start " +
+		"and end), {date} and {time} of the analysis, e.g. reports/{date}/{format}-{time}.md. " +
+		"The report replaces an existing file atomically"
+	colorUsage = "ANSI colors of the text format: auto colors the report written to stdout if it is a terminal " +
+		"and NO_COLOR is empty, always, never"
+	filterUsage = "filter expression selecting the records to analyze, " +
+		"e.g. 'status >= 500 && method == \"POST\" && !(resource =~ \"^/health\")'. " +
+		"Comparisons of a field with a value are combined with &&, ||, ! and parentheses. " +
+		"Operators ==, !=, <, <=, >, >= compare numeric fields as numbers and time_local as time, " +
+		"=~ and !~ match a regular expression, in matches an ip address against a CIDR (e.g. remote_add in 10.0.0.0/8). " +
+		"Available fields: %s and extra.<name> for log variables without a field of their own"
+	fieldUsage = "Filter by log field, a shorthand for -filter '<field> =~ \"<value>\"'. " +
 		"If a filter is specified, the -filter-value must be specified"
 	valueUsage   = "The value of the filter field"
 	highestUsage = "the number of the most common instances of characteristics that should be displayed on the screen" +
 		" (if the available number of instances is exceeded, all are displayed)"
 	readUsage = "the number of lines satisfying the flags that need to be read in each file." +
 		"If this number is equal to or exceeds the appropriate number of lines in the file, the entire file will be read"
-	layout = "2006-01-02T15:04:05Z07:00"
+	logFormatUsage = "nginx log_format directive describing the lines of the log files " +
+		"(variables without a log field of their own are kept as extra values)"
+	inputUsage = "input log format (available formats: nginx, common, apache_combined, vhost_combined, json, " +
+		"alb, cloudfront, gcp, auto). " +
+		"With auto the format is detected separately for each file by its first lines"
+	fieldsUsage = "mapping of log fields to JSON keys for the json input format in the form " +
+		"\"status=response.status,remote_addr=client.ip\" (nested keys are separated by a dot)"
+	sampleUsage  = "the number of the first lines of each file by which the format is detected with -input-format auto"
+	onErrorUsage = "handling of lines that can not be parsed: fail stops the analysis, " +
+		"skip counts them in the report, quarantine also writes them to the -quarantine file"
+	quarantineUsage = "file to which lines that can not be parsed are written with -on-error quarantine"
+	accuracyUsage   = "relative accuracy of percentile estimates in (0, 1). " +
+		"Percentiles are estimated in bounded memory once a distribution has more than 1024 values"
+	exactUsage = "store every value and calculate percentiles exactly (memory grows with the number of lines, " +
+		"suitable for small inputs)"
+	workersUsage = "the number of log files processed concurrently (the report does not depend on it, " +
+		"defaults to the number of CPUs)"
+	parseWorkersUsage = "the number of goroutines parsing the lines of each log file. " +
+		"Lines are read in batches, parsed concurrently and added to the report in their order"
+	exportUsage = "export the records satisfying -from, -to, -filter and -read to stdout instead of writing a report " +
+		"(available formats: raw for the original lines, json for JSON lines, csv)"
+	columnsUsage = "comma-separated fields exported with -export json or csv (defaults to all fields)"
+	baseDirUsage = "directory against which a relative -path is resolved (defaults to the current directory)"
+	excludeUsage = "pattern of the log files excluded from -path, may be repeated. A pattern without a separator " +
+		"is matched against the file name, e.g. \"*.gz\", others against the path like -path"
+	extUsage = "comma-separated extensions of the log files selected in directories and by patterns, e.g. .log,.txt " +
+		"(a rotation number and a compression extension are ignored, so access.log.1 and access.log.2.gz have the extension .log). " +
+		"Defaults to selecting the files with text content, including compressed ones"
 )
 
+// Режимы цветов ANSI флага -color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// detectableFormats - форматы входных логов, из которых выбирается формат файла при -input-format auto.
+// При равном количестве распознанных строк предпочтение отдаётся формату, указанному раньше.
+// Формат gcp указан раньше json, так как записи Cloud Logging также являются JSON-строками.
+var detectableFormats = []string{"nginx", "common", "apache_combined", "vhost_combined", "alb", "cloudfront", "gcp", "json"}
+
+// apacheFormats сопоставляет форматам входных логов Apache httpd их директивы LogFormat.
+var apacheFormats = map[string]string{
+	"common":          apache.CommonFormat,
+	"apache_combined": apache.CombinedFormat,
+	"vhost_combined":  apache.VhostCombinedFormat,
+}
+
+// logParser описывает интерфейс парсера строк лога.
+type logParser interface {
+	Parse(lg string) (*log.Record, error)
+}
+
+// patterns - значения повторяемого флага шаблонов путей.
+type patterns []string
+
+func (p *patterns) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patterns) Set(value string) error {
+	*p = append(*p, value)
+
+	return nil
+}
+
+// flags - значения флагов командной строки.
+type flags struct {
+	paths, excludes          patterns
+	baseDir, ext             string
+	from, to, tz, bucket     string
+	format, output, color    string
+	expression, field, value string
+	highest, read            int
+	logFormat, input, fields string
+	sample                   int
+	onError, quarantine      string
+	accuracy                 float64
+	exact                    bool
+	workers, parseWorkers    int
+	export, columns          string
+}
+
 func main() {
-	path := flag.String("path", defaultPath, pathUsage)
-	from := flag.String("from", defaultFrom, fromUsage)
-	to := flag.String("to", defaultTo, toUsage)
-	format := flag.String("format", defaultFormat, formatUsage)
-	field := flag.String("filter-field", defaultField, fieldUsage)
-	value := flag.String("filter-value", defaultValue, valueUsage)
-	highest := flag.Int("highest", defaultHighest, highestUsage)
-	read := flag.Int("read", defaultRead, readUsage)
+	f := parseFlags()
 
-	flag.Parse()
+	// Проверка валидности флагов -tz, -from, -to и -bucket и их парсинг.
+	loc, err := time.LoadLocation(f.tz)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	// Проверка валидности флагов -from и -to и их парсинг.
-	pfrom, pto, err := parseTimes(*from, *to)
+	pfrom, pto, err := parseTimes(f.from, f.to, loc, time.Now())
 	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	bucketSize, err := parseBucket(f.bucket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
 	// Проверка валидности остальных флагов.
-	if !areOtherFlagValuesValid(*path, *format, *field, *value, *highest, *read) {
+	if !areOtherFlagValuesValid(f.paths, f.format, f.field, f.value, f.onError, f.highest, f.read, f.workers, f.parseWorkers, f.accuracy) {
 		os.Exit(1)
 	}
 
-	anlz := application.New(&finder.Finder{}, analyzer.New(&loader.Loader{}, &parser.Parser{}), marker.New(*format), &filer.Filer{})
+	// Отчёт в формате json содержит все значения, если их количество не ограничено флагом -highest явно.
+	if f.format == "json" && !isFlagSpecified("highest") {
+		f.highest = math.MaxInt
+	}
+
+	// Сборка выражения фильтра и проверка его синтаксиса.
+	f.expression, err = filterExpression(f.expression, f.field, f.value)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	err = anlz.Run(
-		*path, pfrom, pto, *format, *field, *value, *highest, *read,
-		*from != defaultFrom, *to != defaultTo, *field != defaultPath,
-	)
+	anlz, exp, quarantineFile, err := newApplication(f, loc, bucketSize, pfrom, pto)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	isFromSpecified, isToSpecified, isFilterSpecified := f.from != defaultFrom, f.to != defaultTo, f.expression != defaultFilter
+
+	if exp != nil {
+		err = anlz.Export(f.paths, pfrom, pto, f.expression, f.read, isFromSpecified, isToSpecified, isFilterSpecified)
+		err = errors.Join(err, exp.Flush())
+	} else {
+		err = anlz.Run(
+			f.paths, pfrom, pto, f.format, f.expression, f.highest, f.read,
+			isFromSpecified, isToSpecified, isFilterSpecified,
+		)
+	}
+
+	if quarantineFile != nil {
+		quarantineFile.Close()
+	}
 
 	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
 		os.Exit(1)
 	}
 }
 
-// parseTimes парсит значения флагов -from и -to.
-func parseTimes(from, to string) (pfrom, pto time.Time, err error) {
+// parseFlags определяет флаги командной строки и возвращает их значения.
+func parseFlags() *flags {
+	f := &flags{}
+
+	flag.Var(&f.paths, "path", pathUsage)
+	flag.StringVar(&f.baseDir, "base-dir", defaultBaseDir, baseDirUsage)
+	flag.Var(&f.excludes, "exclude", excludeUsage)
+	flag.StringVar(&f.ext, "ext", defaultExt, extUsage)
+	flag.StringVar(&f.from, "from", defaultFrom, fromUsage)
+	flag.StringVar(&f.to, "to", defaultTo, toUsage)
+	flag.StringVar(&f.tz, "tz", defaultTZ, tzUsage)
+	flag.StringVar(&f.bucket, "bucket", defaultBucket, bucketUsage)
+	flag.StringVar(&f.format, "format", defaultFormat, formatUsage)
+	flag.StringVar(&f.output, "output", defaultOutput, outputUsage)
+	flag.StringVar(&f.color, "color", defaultColor, colorUsage)
+	flag.StringVar(&f.expression, "filter", defaultFilter, fmt.Sprintf(filterUsage, strings.Join(log.FieldNames(), ", ")))
+	flag.StringVar(&f.field, "filter-field", defaultField, fieldUsage)
+	flag.StringVar(&f.value, "filter-value", defaultValue, valueUsage)
+	flag.IntVar(&f.highest, "highest", defaultHighest, highestUsage)
+	flag.IntVar(&f.read, "read", defaultRead, readUsage)
+	flag.StringVar(&f.logFormat, "log-format", defaultLogFormat, logFormatUsage)
+	flag.StringVar(&f.input, "input-format", defaultInput, inputUsage)
+	flag.StringVar(&f.fields, "json-fields", defaultFields, fieldsUsage)
+	flag.IntVar(&f.sample, "detect-lines", defaultSample, sampleUsage)
+	flag.StringVar(&f.onError, "on-error", defaultOnError, onErrorUsage)
+	flag.StringVar(&f.quarantine, "quarantine", defaultQuarantine, quarantineUsage)
+	flag.Float64Var(&f.accuracy, "percentile-accuracy", defaultAccuracy, accuracyUsage)
+	flag.BoolVar(&f.exact, "exact-percentiles", defaultExact, exactUsage)
+	flag.IntVar(&f.workers, "workers", runtime.NumCPU(), workersUsage)
+	flag.IntVar(&f.parseWorkers, "parse-workers", defaultParse, parseWorkersUsage)
+	flag.StringVar(&f.export, "export", defaultExport, exportUsage)
+	flag.StringVar(&f.columns, "columns", defaultColumns, columnsUsage)
+
+	flag.Parse()
+
+	return f
+}
+
+// newApplication возвращает приложение, собранное по значениям флагов f, часовому поясу loc, длительности интервалов
+// bucketSize и границам времени pfrom и pto, а также экспортёр записей в режиме экспорта и файл карантина,
+// если они используются. Файл карантина нужно закрыть после запуска приложения.
+func newApplication(
+	f *flags, loc *time.Location, bucketSize time.Duration, pfrom, pto time.Time,
+) (*application.Application, *exporter.Exporter, *os.File, error) {
+	// Определение терминала, в который выводится отчёт формата text.
+	terminal, err := newTerminal(f.output, f.color)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Создание парсера, соответствующего формату входных логов.
+	ps, err := newParser(f.input, f.logFormat, f.fields, f.sample)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfg := analyzer.Config{
+		OnError:            f.onError,
+		PercentileAccuracy: f.accuracy,
+		ExactPercentiles:   f.exact,
+		Workers:            f.workers,
+		ParseWorkers:       f.parseWorkers,
+		BucketSize:         bucketSize,
+		Location:           loc,
+	}
+
+	// Создание экспортёра записей в режиме экспорта.
+	exp, err := newExporter(f.export, f.columns)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if exp != nil {
+		cfg.Exporter = exp
+	}
+
+	// Создание файла карантина для строк, которые не удалось распарсить.
+	var quarantineFile *os.File
+
+	if f.onError == analyzer.OnErrorQuarantine {
+		quarantineFile, err = os.Create(f.quarantine)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("can`t create quarantine file: %w", err)
+		}
+
+		cfg.Quarantine = quarantineFile
+	}
+
+	fnd := &finder.Finder{BaseDir: f.baseDir, Exclude: f.excludes, Extensions: splitExtensions(f.ext)}
+	flr := &filer.Filer{Output: f.output, From: pfrom, To: pto}
+
+	anlz := analyzer.New(&loader.Loader{}, ps, cfg)
+
+	// Отчёты форматов csv и tsv размечаются по таблицам, каждая из которых сохраняется в отдельный файл.
+	if tm, ok := marker.NewTables(f.format); ok {
+		return application.NewTables(fnd, anlz, tm, flr), exp, quarantineFile, nil
+	}
+
+	return application.New(fnd, anlz, marker.New(f.format, terminal), flr), exp, quarantineFile, nil
+}
+
+// newParser возвращает парсер строк лога, соответствующий формату input.
+// Для формата auto возвращает детектор, выбирающий для каждого файла формат по первым sample строкам.
+func newParser(input, logFormat, fields string, sample int) (logParser, error) {
+	switch input {
+	case "auto":
+		if sample <= 0 {
+			return nil, fmt.Errorf("the number of lines %d for format detection is not positive", sample)
+		}
+
+		candidates := make([]detector.Candidate, 0, len(detectableFormats))
+
+		for _, format := range detectableFormats {
+			ps, err := newParser(format, logFormat, fields, sample)
+			if err != nil {
+				return nil, fmt.Errorf("can`t create parser for %s format: %w", format, err)
+			}
+
+			candidates = append(candidates, detector.Candidate{Format: format, Parser: ps})
+		}
+
+		return detector.New(sample, candidates...), nil
+	case "nginx":
+		ps, err := parser.New(logFormat)
+		if err != nil {
+			return nil, fmt.Errorf("can`t compile log format %s: %w", logFormat, err)
+		}
+
+		return ps, nil
+	case "common", "apache_combined", "vhost_combined":
+		ps, err := apache.New(apacheFormats[input])
+		if err != nil {
+			return nil, fmt.Errorf("can`t compile log format %s: %w", apacheFormats[input], err)
+		}
+
+		return ps, nil
+	case "json":
+		ps, err := jsonl.New(fields)
+		if err != nil {
+			return nil, fmt.Errorf("can`t create json parser: %w", err)
+		}
+
+		return ps, nil
+	case "alb":
+		return &alb.Parser{}, nil
+	case "cloudfront":
+		return &cloudfront.Parser{}, nil
+	case "gcp":
+		return &gcp.Parser{}, nil
+	default:
+		return nil, fmt.Errorf("unknown input format %s", input)
+	}
+}
+
+// parseBucket парсит длительность интервалов времени флага -bucket: длительность Go, например 5m или 1h,
+// или количество суток, например 1d. Длительность должна делить сутки или быть кратной им,
+// чтобы интервалы выравнивались по полуночи. Если флаг не задан, возвращает 0.
+func parseBucket(bucket string) (time.Duration, error) {
+	if bucket == defaultBucket {
+		return 0, nil
+	}
+
+	var (
+		size time.Duration
+		err  error
+	)
+
+	if days, ok := strings.CutSuffix(bucket, "d"); ok {
+		var count int
+
+		count, err = strconv.Atoi(days)
+		size = time.Duration(count) * 24 * time.Hour
+	} else {
+		size, err = time.ParseDuration(bucket)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("can`t parse bucket %s: %w", bucket, err)
+	}
+
+	const day = 24 * time.Hour
+
+	if size <= 0 || day%size != 0 && size%day != 0 {
+		return 0, fmt.Errorf("bucket %s is not a positive divisor or multiple of a day", bucket)
+	}
+
+	return size, nil
+}
+
+// splitExtensions возвращает расширения, перечисленные через запятую в ext, дополняя их точкой в начале.
+func splitExtensions(ext string) []string {
+	if ext == defaultExt {
+		return nil
+	}
+
+	extensions := strings.Split(ext, ",")
+
+	for i := range extensions {
+		extensions[i] = strings.TrimSpace(extensions[i])
+
+		if !strings.HasPrefix(extensions[i], ".") {
+			extensions[i] = "." + extensions[i]
+		}
+	}
+
+	return extensions
+}
+
+// newExporter возвращает экспортёр записей в стандартный вывод в формате export со столбцами columns,
+// перечисленными через запятую. Если режим экспорта не задан, возвращает nil.
+func newExporter(export, columns string) (*exporter.Exporter, error) {
+	if export == defaultExport {
+		return nil, nil
+	}
+
+	var names []string
+
+	if columns != defaultColumns {
+		names = strings.Split(columns, ",")
+
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+	}
+
+	exp, err := exporter.New(os.Stdout, export, names)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -export: %w", err)
+	}
+
+	return exp, nil
+}
+
+// filterExpression возвращает выражение фильтра флага -filter или, если указан флаг -filter-field, выражение
+// соответствия поля field регулярному выражению value, и проверяет синтаксис выражения.
+func filterExpression(expression, field, value string) (string, error) {
+	if field != defaultField {
+		if expression != defaultFilter {
+			return "", fmt.Errorf("-filter can`t be specified together with -filter-field")
+		}
+
+		expression = fmt.Sprintf("%s =~ %s", field, strconv.Quote(value))
+	}
+
+	if expression == defaultFilter {
+		return expression, nil
+	}
+
+	_, err := filter.New(expression)
+	if err != nil {
+		return "", fmt.Errorf("invalid -filter: %w", err)
+	}
+
+	return expression, nil
+}
+
+// newTerminal возвращает терминал, в который выводится отчёт с выводом output, и проверяет значение флага -color.
+// Ширина терминала известна, только если отчёт выводится в стандартный вывод, являющийся терминалом.
+func newTerminal(output, color string) (marker.Terminal, error) {
+	var terminal marker.Terminal
+
+	isTerminal := output == filer.Stdout && term.IsTerminal(int(os.Stdout.Fd()))
+
+	if isTerminal {
+		width, _, err := term.GetSize(int(os.Stdout.Fd()))
+		if err == nil {
+			terminal.Width = width
+		}
+	}
+
+	switch color {
+	case colorAuto:
+		terminal.Color = isTerminal && os.Getenv("NO_COLOR") == ""
+	case colorAlways:
+		terminal.Color = true
+	case colorNever:
+	default:
+		return marker.Terminal{}, fmt.Errorf("unknown color mode %s", color)
+	}
+
+	return terminal, nil
+}
+
+// isFlagSpecified проверяет, указан ли флаг name в командной строке.
+func isFlagSpecified(name string) bool {
+	specified := false
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			specified = true
+		}
+	})
+
+	return specified
+}
+
+// parseTimes парсит значения флагов -from и -to относительно текущего момента now в часовом поясе loc.
+func parseTimes(from, to string, loc *time.Location, now time.Time) (pfrom, pto time.Time, err error) {
 	if from != defaultFrom {
-		pfrom, err = time.Parse(layout, from)
+		pfrom, err = timespec.Parse(from, now, loc)
 		if err != nil {
 			return pfrom, pto, fmt.Errorf("can`t parse time from %s: %w", from, err)
 		}
 	}
 
 	if to != defaultTo {
-		pto, err = time.Parse(layout, to)
+		pto, err = timespec.Parse(to, now, loc)
 		if err != nil {
-			return pfrom, pto, fmt.Errorf("can`t parse time from %s: %w", from, err)
+			return pfrom, pto, fmt.Errorf("can`t parse time to %s: %w", to, err)
 		}
 	}
 
+	if from != defaultFrom && to != defaultTo && pfrom.After(pto) {
+		return pfrom, pto, fmt.Errorf("time from %s is after time to %s", pfrom.Format(time.RFC3339), pto.Format(time.RFC3339))
+	}
+
 	return pfrom, pto, nil
 }
 
-// areOtherFlagValuesValid проверяет, валидны ли значения флагов path, format, fielld, value, highest, read.
-func areOtherFlagValuesValid(path, format, field, value string, highest, read int) bool {
-	// Доступные значения filter-fields соответствуют формату nginx-лога, но request разбит на method, resource, protocol.
-	fields := map[string]bool{
-		"remote_add":      true,
-		"remote_user":     true,
-		"time_local":      true,
-		"method":          true,
-		"resource":        true,
-		"protocol":        true,
-		"status":          true,
-		"body_bytes_sent": true,
-		"http_referer":    true,
-		"http_user_agent": true,
+// areOtherFlagValuesValid проверяет, валидны ли значения флагов path, format, filter-field, filter-value, on-error, highest, read,
+// workers, parse-workers, percentile-accuracy.
+func areOtherFlagValuesValid(
+	paths []string, format, field, value, onError string, highest, read, workers, parseWorkers int, accuracy float64,
+) bool {
+	formats := map[string]bool{
+		"markdown":    true,
+		"adoc":        true,
+		"html":        true,
+		"json":        true,
+		"csv":         true,
+		"csv-zip":     true,
+		"tsv":         true,
+		"tsv-zip":     true,
+		"openmetrics": true,
+		"text":        true,
 	}
 
-	formats := map[string]bool{
-		"markdown": true,
-		"adoc":     true,
+	onErrors := map[string]bool{
+		analyzer.OnErrorFail:       true,
+		analyzer.OnErrorSkip:       true,
+		analyzer.OnErrorQuarantine: true,
+	}
+
+	if len(paths) == 0 {
+		return false
 	}
 
-	if path == defaultPath {
+	if _, ok := onErrors[onError]; !ok {
 		return false
 	}
 
@@ -125,11 +603,16 @@ func areOtherFlagValuesValid(path, format, field, value string, highest, read in
 		return false
 	}
 
-	if _, ok := fields[field]; !ok && field != defaultField || ok && value == defaultValue {
+	// Доступные значения filter-field соответствуют полям log.Record, но request разбит на method, resource, protocol.
+	if ok := log.IsField(field); !ok && field != defaultField || ok && value == defaultValue {
+		return false
+	}
+
+	if highest <= 0 || read <= 0 || workers <= 0 || parseWorkers <= 0 {
 		return false
 	}
 
-	if highest <= 0 || read <= 0 {
+	if accuracy <= 0 || accuracy >= 1 {
 		return false
 	}
 
@@ -3,12 +3,18 @@ module github.com/es-debug/backend-academy-2024-go-template
 go 1.22.6
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.10.0
+	github.com/klauspost/compress v1.18.0
 	github.com/montanaflynn/stats v0.7.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/stretchr/testify v1.9.0
+	github.com/ulikunitz/xz v0.5.15
+	golang.org/x/term v0.29.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
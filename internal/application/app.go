@@ -9,15 +9,15 @@ import (
 )
 
 type finder interface {
-	// Find ищет путь/пути по переданному path, соответствующему локальному шаблоном или url.
-	Find(path string) (paths []string, isLocal bool, err error)
+	// Find ищет пути по переданным patterns, соответствующим локальным шаблонам или url.
+	Find(patterns []string) (paths []string, isLocal bool, err error)
 }
 
 type analyzer interface {
 	// Analyze анализирует логи по указанным путям в соответствии с флагами и возвращает готовый для разметки отчёт.
 	Analyze(
 		from, to time.Time,
-		field, value string,
+		filter string,
 		read int,
 		isFromSpecified, isToSpecified, isFilterSpecified bool,
 		paths []string, isLocal bool,
@@ -29,17 +29,25 @@ type marker interface {
 	MarkUp(rep *report.Report, highest int) (markup string)
 }
 
+type tableMarker interface {
+	// MarkUpTables размечает таблицы отчёта, возвращая содержимое их файлов по именам.
+	MarkUpTables(rep *report.Report, highest int) (tables map[string][]byte, err error)
+}
+
 type filer interface {
 	// File создаёт файл с расширением соответствующего формата, содержащий размеченный отчёт.
 	File(markup, format string) (file *os.File, err error)
+	// Tables сохраняет файлы таблиц отчёта в директорию или архив соответствующего формата.
+	Tables(tables map[string][]byte, format string) (file *os.File, err error)
 }
 
 // Application описывает приложение анализатора.
 type Application struct {
-	finder   finder
-	analyzer analyzer
-	marker   marker
-	filer    filer
+	finder      finder
+	analyzer    analyzer
+	marker      marker
+	tableMarker tableMarker // Разметчик таблиц, используемый вместо marker, если он задан.
+	filer       filer
 }
 
 // New возвращает инициализированный Application.
@@ -52,23 +60,28 @@ func New(finder finder, solver analyzer, packer marker, writer filer) *Applicati
 	}
 }
 
+// NewTables возвращает инициализированный Application, размечающий каждую таблицу отчёта в отдельный файл.
+func NewTables(finder finder, solver analyzer, packer tableMarker, writer filer) *Application {
+	return &Application{
+		finder:      finder,
+		analyzer:    solver,
+		tableMarker: packer,
+		filer:       writer,
+	}
+}
+
 // Run запускает приложение.
 func (a *Application) Run(
-	path string, from, to time.Time, format, field, value string, highest, read int,
+	patterns []string, from, to time.Time, format, filter string, highest, read int,
 	isFromSpecified, isToSpecified, isFilterSpecified bool,
 ) error {
-	paths, isLocal, err := a.finder.Find(path)
+	rep, err := a.analyze(patterns, from, to, filter, read, isFromSpecified, isToSpecified, isFilterSpecified)
 	if err != nil {
-		return fmt.Errorf("can`t find paths to files: %w", err)
+		return err
 	}
 
-	rep, err := a.analyzer.Analyze(
-		from, to, field, value, read,
-		isFromSpecified, isToSpecified, isFilterSpecified,
-		paths, isLocal,
-	)
-	if err != nil {
-		return fmt.Errorf("can`t solve: %w", err)
+	if a.tableMarker != nil {
+		return a.writeTables(&rep, format, highest)
 	}
 
 	markup := a.marker.MarkUp(&rep, highest)
@@ -80,3 +93,51 @@ func (a *Application) Run(
 
 	return nil
 }
+
+// writeTables размечает таблицы отчёта rep и сохраняет их файлы.
+func (a *Application) writeTables(rep *report.Report, format string, highest int) error {
+	tables, err := a.tableMarker.MarkUpTables(rep, highest)
+	if err != nil {
+		return fmt.Errorf("can`t mark up rep tables: %w", err)
+	}
+
+	_, err = a.filer.Tables(tables, format)
+	if err != nil {
+		return fmt.Errorf("can`t write rep tables to files: %w", err)
+	}
+
+	return nil
+}
+
+// Export запускает приложение в режиме экспорта: записи, удовлетворяющие флагам, передаются экспортёру анализатора,
+// а отчёт не размечается и не записывается в файл.
+func (a *Application) Export(
+	patterns []string, from, to time.Time, filter string, read int,
+	isFromSpecified, isToSpecified, isFilterSpecified bool,
+) error {
+	_, err := a.analyze(patterns, from, to, filter, read, isFromSpecified, isToSpecified, isFilterSpecified)
+
+	return err
+}
+
+// analyze находит файлы по patterns и анализирует их.
+func (a *Application) analyze(
+	patterns []string, from, to time.Time, filter string, read int,
+	isFromSpecified, isToSpecified, isFilterSpecified bool,
+) (report.Report, error) {
+	paths, isLocal, err := a.finder.Find(patterns)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("can`t find paths to files: %w", err)
+	}
+
+	rep, err := a.analyzer.Analyze(
+		from, to, filter, read,
+		isFromSpecified, isToSpecified, isFilterSpecified,
+		paths, isLocal,
+	)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("can`t solve: %w", err)
+	}
+
+	return rep, nil
+}
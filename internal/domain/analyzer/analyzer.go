@@ -2,17 +2,32 @@ package analyzer
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
-	"strconv"
+	"slices"
+	"sort"
+	"sync/atomic"
 	"time"
 
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/detector"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/filter"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	logparser "github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/quantile"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
-	"github.com/montanaflynn/stats"
 )
 
+// Режимы обработки строк лога, которые не удалось распарсить.
+const (
+	OnErrorFail       = "fail"       // Анализ прерывается с ошибкой.
+	OnErrorSkip       = "skip"       // Строка пропускается и учитывается в отчёте.
+	OnErrorQuarantine = "quarantine" // Строка пропускается, учитывается в отчёте и записывается в карантин.
+)
+
+// DefaultResourceLatencies - количество ресурсов, для которых по умолчанию хранится время обработки запросов.
+const DefaultResourceLatencies = 1024
+
 // loader описывает интерфейс загрузчика.
 type loader interface {
 	Load(path string, isLocal bool) (io.ReadCloser, error)
@@ -23,68 +38,107 @@ type parser interface {
 	Parse(lg string) (*log.Record, error) // Parse парсит строку nginx лога в log.Record.
 }
 
-// statistics хранит промежуточную статистику и метаданные.
-// Вся необходимая для формирования отчёта информация хранится в этой структуре.
-// В некотором смысле statistics - промежуточное представление отчёта.
-type statistics struct {
-	from              string
-	to                string
-	field             string
-	value             string
-	requestsCount     int
-	totalResponseSize int
-	files             []string
-	responseSizes     []float64
-	resources         map[string]int
-	codes             map[int]int
-	clients           map[string]int
-	agents            map[string]int
+// exporter описывает интерфейс получателя записей лога, удовлетворяющих условиям.
+type exporter interface {
+	Export(line string, record *log.Record) error // Export записывает запись record, полученную из строки line.
+}
+
+// forker описывает интерфейс парсера, хранящего состояние, которое относится к одному файлу,
+// например порядок полей из заголовка файла. Строки каждого файла парсятся отдельной функцией, полученной от Fork.
+type forker interface {
+	Fork() func(lg string) (*log.Record, error) // Fork возвращает функцию парсинга с собственным состоянием.
+}
+
+// statefulDetector описывает интерфейс детектора, сообщающего, хранит ли парсер формата состояние,
+// относящееся к одному файлу. Строки файлов таких форматов парсятся последовательно.
+type statefulDetector interface {
+	IsStateful(format string) bool
+}
+
+// formatDetector описывает интерфейс парсера, умеющего определять формат лога для каждого файла.
+// Если parser, переданный в New, реализует formatDetector, формат определяется по первым строкам каждого файла.
+type formatDetector interface {
+	// Detect определяет формат лога по первым строкам source и возвращает его название, функцию парсинга
+	// и источник, заново содержащий считанные строки.
+	Detect(source io.Reader) (format string, parse func(lg string) (*log.Record, error), replay io.Reader, err error)
+}
+
+// Config - настройки обработки логов.
+// Нулевое значение Config соответствует режиму OnErrorFail.
+// Нулевая PercentileAccuracy соответствует quantile.DefaultAccuracy.
+type Config struct {
+	OnError            string    // Режим обработки строк, которые не удалось распарсить.
+	Quarantine         io.Writer // Получатель строк, которые не удалось распарсить, в режиме OnErrorQuarantine.
+	PercentileAccuracy float64   // Относительная погрешность оценки перцентилей.
+	ExactPercentiles   bool      // Указывает необходимость хранить все значения и вычислять перцентили точно.
+	Workers            int       // Количество файлов, обрабатываемых одновременно. Значения меньше 1 соответствуют 1.
+	ParseWorkers       int       // Количество горутин, парсящих строки одного файла. Значения меньше 2 отключают конвейер.
+	// ResourceLatencies - количество ресурсов с наибольшим количеством запросов с известным временем обработки,
+	// для которых хранится время обработки запросов, чтобы память не росла с количеством различных ресурсов.
+	// Время обработки запросов к ресурсу, вытесненному и снова добавленному, учитывается с момента добавления.
+	// Значения меньше 1 соответствуют DefaultResourceLatencies.
+	ResourceLatencies int
+	// BucketSize - длительность интервалов времени, по которым собирается статистика запросов.
+	// Нулевое значение отключает статистику по интервалам.
+	BucketSize time.Duration
+	// Location - часовой пояс, по полуночи которого выравниваются интервалы времени. nil соответствует UTC.
+	Location *time.Location
+	// Exporter - получатель записей, удовлетворяющих условиям, в порядке файлов и строк.
+	// Если Exporter задан, файлы обрабатываются последовательно.
+	Exporter exporter
+}
+
+// newSketch возвращает указатель на quantile.Sketch, оценивающий перцентили в соответствии с настройками.
+func (cfg *Config) newSketch() *quantile.Sketch {
+	if cfg.ExactPercentiles {
+		return quantile.NewExact()
+	}
+
+	return quantile.New(cfg.PercentileAccuracy, quantile.DefaultCapacity)
 }
 
 // Analyzer - структура внутреннего анализатора логов.
 type Analyzer struct {
-	loader            loader     // Загрузчик данных для чтения.
-	parser            parser     // Парсер строк лога.
-	stats             statistics // Статистика, которая будет использована для формирования отчёта.
-	from              time.Time  // Нижний предел времени лога, подлежащего анализу.
-	to                time.Time  // Верхний предел времени лога, подлежащего анализу.
-	field             string     // Фильтруемое поле.
-	value             string     // Значение фильтруемого поля.
-	read              int        // Количество строк, удовлетворяющих условиям, которые нужно прочесть из каждого лога.
-	isFromSpecified   bool       // Указывает небходимость использовать from.
-	isToSpecified     bool       // Указывает небходимость использовать to.
-	isFilterSpecified bool       // Указывает небходимость использовать field и value.
+	loader            loader         // Загрузчик данных для чтения.
+	parser            parser         // Парсер строк лога.
+	config            Config         // Настройки обработки логов.
+	stats             statistics     // Статистика, которая будет использована для формирования отчёта.
+	from              time.Time      // Нижний предел времени лога, подлежащего анализу.
+	to                time.Time      // Верхний предел времени лога, подлежащего анализу.
+	filter            *filter.Filter // Фильтр записей лога.
+	read              int            // Количество строк, удовлетворяющих условиям, которые нужно прочесть из каждого лога.
+	isFromSpecified   bool           // Указывает небходимость использовать from.
+	isToSpecified     bool           // Указывает небходимость использовать to.
+	isFilterSpecified bool           // Указывает небходимость использовать field и value.
 }
 
 // New возвращает указатель на инициализованный Analyzer.
-func New(ld loader, ps parser) *Analyzer {
+func New(ld loader, ps parser, cfg Config) *Analyzer {
 	return &Analyzer{
 		loader: ld,
 		parser: ps,
-		stats: statistics{
-			resources: make(map[string]int),
-			codes:     make(map[int]int),
-			clients:   make(map[string]int),
-			agents:    make(map[string]int),
-		},
+		config: cfg,
+		stats:  *newStatistics(&cfg),
 	}
 }
 
 // Analyze анализирует логи по указанным путям в соответствии с флагами и возвращает готовый для разметки отчёт.
+// Записи фильтруются выражением expression, синтаксис которого описан в filter.Filter.
 func (a *Analyzer) Analyze(
 	from, to time.Time,
-	field, value string,
+	expression string,
 	read int,
 	isFromSpecified, isToSpecified, isFilterSpecified bool,
 	paths []string, isLocal bool,
 ) (rep report.Report, err error) {
-	a.assignInitialData(from, to, field, value, paths, read, isFromSpecified, isToSpecified, isFilterSpecified)
+	err = a.assignInitialData(from, to, expression, paths, read, isFromSpecified, isToSpecified, isFilterSpecified)
+	if err != nil {
+		return rep, fmt.Errorf("can`t assign initial data: %w", err)
+	}
 
-	for _, path := range paths {
-		err = a.ProcessLogFile(path, isLocal)
-		if err != nil {
-			return rep, fmt.Errorf("can`t process log file: %w", err)
-		}
+	err = a.processLogFiles(paths, isLocal)
+	if err != nil {
+		return rep, fmt.Errorf("can`t process log files: %w", err)
 	}
 
 	rep, err = generateReport(&a.stats)
@@ -95,76 +149,207 @@ func (a *Analyzer) Analyze(
 	return rep, nil
 }
 
-// assignInitialData записывает полученные данные в Analyzer.
+// assignInitialData записывает полученные данные в Analyzer, компилируя выражение фильтра expression.
+// Заданные границы отрезка времени записываются в статистику в формате RFC 3339 в их часовом поясе.
 func (a *Analyzer) assignInitialData(
 	from, to time.Time,
-	field, value string,
+	expression string,
 	paths []string,
 	read int,
 	isFromSpecified, isToSpecified, isFilterSpecified bool,
-) {
+) error {
+	if isFilterSpecified {
+		f, err := filter.New(expression)
+		if err != nil {
+			return fmt.Errorf("can`t create filter: %w", err)
+		}
+
+		a.filter = f
+	}
+
 	if isFromSpecified {
-		a.stats.from = from.String()
+		a.stats.from = from.Format(time.RFC3339)
 	} else {
 		a.stats.from = "-"
 	}
 
 	if isToSpecified {
-		a.stats.to = to.String()
+		a.stats.to = to.Format(time.RFC3339)
 	} else {
 		a.stats.to = "-"
 	}
 
 	a.stats.files = append(a.stats.files, paths...)
-	a.stats.field = field
-	a.stats.value = value
+	a.stats.filter = expression
 
 	a.from = from
 	a.to = to
-	a.field = field
-	a.value = value
 	a.read = read
 	a.isFromSpecified = isFromSpecified
 	a.isToSpecified = isToSpecified
 	a.isFilterSpecified = isFilterSpecified
+
+	return nil
 }
 
-// ProcessLogFile обрабатывает файл.
+// processLogFiles обрабатывает файлы paths, одновременно обрабатывая до config.Workers файлов.
+// Статистика каждого файла собирается отдельно и добавляется в поле статистики Analyzer в порядке paths,
+// поэтому отчёт не зависит от количества одновременно обрабатываемых файлов.
+// Если задан config.Exporter, файлы обрабатываются последовательно, чтобы записи экспортировались в порядке файлов.
+func (a *Analyzer) processLogFiles(paths []string, isLocal bool) error {
+	results := make([]*statistics, len(paths))
+	errs := make([]error, len(paths))
+	done := make([]chan struct{}, len(paths))
+
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+
+	var failed atomic.Bool // Указывает, что обработка одного из файлов завершилась ошибкой.
+
+	workers := max(a.config.Workers, 1)
+	if a.config.Exporter != nil {
+		workers = 1
+	}
+
+	for range min(workers, len(paths)) {
+		go func() {
+			for i := range jobs {
+				if !failed.Load() {
+					results[i], errs[i] = a.processLogFile(paths[i], isLocal)
+
+					if errs[i] != nil {
+						failed.Store(true)
+					}
+				}
+
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range paths {
+			jobs <- i
+		}
+
+		close(jobs)
+	}()
+
+	var err error
+
+	for i := range paths {
+		<-done[i]
+
+		if err == nil && errs[i] != nil {
+			err = fmt.Errorf("can`t process log file %s: %w", paths[i], errs[i])
+		} else if err == nil && results[i] != nil {
+			err = a.mergeStatistics(results[i])
+		}
+
+		results[i] = nil // Статистика файла больше не нужна.
+	}
+
+	return err
+}
+
+// ProcessLogFile обрабатывает файл, добавляя результаты анализа в поле статистики Analyzer.
 func (a *Analyzer) ProcessLogFile(path string, isLocal bool) error {
+	st, err := a.processLogFile(path, isLocal)
+	if err != nil {
+		return err
+	}
+
+	return a.mergeStatistics(st)
+}
+
+// processLogFile обрабатывает файл и возвращает его статистику.
+func (a *Analyzer) processLogFile(path string, isLocal bool) (*statistics, error) {
 	source, err := a.loader.Load(path, isLocal)
 	if err != nil {
-		return fmt.Errorf("can`t load log file: %w", err)
+		return nil, fmt.Errorf("can`t load log file: %w", err)
 	}
 	defer source.Close()
 
-	err = a.addToStatisticsFromLog(source)
+	st := newStatistics(&a.config)
+
+	var lg io.Reader = source
+
+	parse := a.parser.Parse
+	isStateful := false
+
+	if dt, ok := a.parser.(formatDetector); ok {
+		var format string
+
+		format, parse, lg, err = dt.Detect(source)
+		if errors.As(err, &detector.ErrUnknownFormat{}) { // Файл, не соответствующий ни одному формату, не анализируется.
+			st.unrecognized = append(st.unrecognized, path)
+
+			return st, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("can`t detect log format: %w", err)
+		}
+
+		st.formats = append(st.formats, report.FileFormat{File: path, Format: format})
+
+		if sd, ok := dt.(statefulDetector); ok {
+			isStateful = sd.IsStateful(format)
+		}
+	} else if fk, ok := a.parser.(forker); ok {
+		parse = fk.Fork()
+		isStateful = true
+	}
+
+	if a.config.ParseWorkers > 1 && !isStateful {
+		err = a.addToStatisticsFromLogConcurrently(st, path, lg, parse, a.config.ParseWorkers)
+	} else {
+		err = a.addToStatisticsFromLog(st, path, lg, parse)
+	}
+
 	if err != nil {
-		return fmt.Errorf("can`t add log to statistics: %w", err)
+		return nil, fmt.Errorf("can`t add log to statistics: %w", err)
+	}
+
+	return st, nil
+}
+
+// mergeStatistics добавляет статистику файла st в поле статистики Analyzer
+// и записывает строки карантина файла в карантин.
+func (a *Analyzer) mergeStatistics(st *statistics) error {
+	a.stats.merge(st)
+
+	if a.config.Quarantine != nil && st.quarantine.Len() != 0 {
+		_, err := st.quarantine.WriteTo(a.config.Quarantine)
+		if err != nil {
+			return fmt.Errorf("can`t write lines to quarantine: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// addToStatisticsFromLog анализирует lg построчно, добавляя результаты анализа в поле статистики Analyzer.
-func (a *Analyzer) addToStatisticsFromLog(lg io.Reader) error {
+// addToStatisticsFromLog анализирует lg файла path построчно с помощью parse, добавляя результаты анализа в st.
+func (a *Analyzer) addToStatisticsFromLog(
+	st *statistics, path string, lg io.Reader, parse func(lg string) (*log.Record, error),
+) error {
 	scn := bufio.NewScanner(lg)
 	linesRead := 0
+	lineNumber := 0
 
 	for scn.Scan() && linesRead < a.read {
-		logRecord, err := a.parser.Parse(scn.Text())
-		if err != nil {
-			return fmt.Errorf("can`t parse scan result: %w", err)
-		}
+		lineNumber++
+
+		line := a.parseLine(parse, lineNumber, scn.Text())
 
-		isCheckSuccessful, err := a.check(logRecord)
+		isRead, err := a.addToStatisticsFromParsedLine(st, path, &line)
 		if err != nil {
-			return fmt.Errorf("can't check the lg to satisfy the conditions: %w", err)
+			return err
 		}
 
-		if isCheckSuccessful {
+		if isRead {
 			linesRead++
-
-			a.addToStatisticsFromLogRecord(logRecord)
 		}
 	}
 
@@ -175,21 +360,132 @@ func (a *Analyzer) addToStatisticsFromLog(lg io.Reader) error {
 	return nil
 }
 
-// addToStatisticsFromLogRecord анализирует logRecord, добавляя результаты анализа в поле статистики Analyzer.
-func (a *Analyzer) addToStatisticsFromLogRecord(logRecord *log.Record) {
-	a.stats.requestsCount++
-	a.stats.resources[logRecord.Request.Resource]++
-	a.stats.codes[logRecord.Status]++
-	a.stats.clients[logRecord.RemoteAddr]++
-	a.stats.agents[logRecord.HTTPUserAgent]++
-	a.stats.responseSizes = append(a.stats.responseSizes, float64(logRecord.BodyBytesSent))
-	a.stats.totalResponseSize += logRecord.BodyBytesSent
+// addToStatisticsFromLogRecord анализирует logRecord, добавляя результаты анализа в st.
+func (a *Analyzer) addToStatisticsFromLogRecord(st *statistics, logRecord *log.Record) {
+	st.requestsCount++
+	st.resources[logRecord.Request.Resource]++
+	st.codes[logRecord.Status]++
+	st.clients[logRecord.RemoteAddr]++
+	st.agents[logRecord.HTTPUserAgent]++
+	st.responseSizes.Add(float64(logRecord.BodyBytesSent))
+	st.totalResponseSize += logRecord.BodyBytesSent
+
+	latency, isLatencyKnown := getLatency(logRecord)
+
+	if a.config.BucketSize > 0 {
+		a.addToBucket(st, logRecord, latency, isLatencyKnown)
+	}
+
+	if isLatencyKnown {
+		resourceLatencies, ok := st.resourceLatencies[logRecord.Request.Resource]
+		if !ok {
+			resourceLatencies = a.config.newSketch()
+			st.resourceLatencies[logRecord.Request.Resource] = resourceLatencies
+		}
+
+		st.latencies.Add(latency)
+		resourceLatencies.Add(latency)
+
+		if !ok {
+			st.pruneResourceLatencies()
+		}
+	}
 }
 
-// check проверяет, соответствует ли запись лога отрезку времени анализа и фильтру.
-func (a *Analyzer) check(record *log.Record) (bool, error) {
-	var err error
+// addToBucket добавляет logRecord со временем обработки latency, если оно известно, в статистику интервала времени st.
+func (a *Analyzer) addToBucket(st *statistics, logRecord *log.Record, latency float64, isLatencyKnown bool) {
+	start := bucketStart(logRecord.TimeLocal, a.config.BucketSize, a.config.Location)
+
+	bucket, ok := st.buckets[start.UnixNano()]
+	if !ok {
+		bucket = &bucketStatistics{start: start, statusClasses: make([]int, len(report.StatusClasses))}
+		st.buckets[start.UnixNano()] = bucket
+	}
+
+	bucket.requestsCount++
+	bucket.bytesSent += logRecord.BodyBytesSent
 
+	if class, ok := report.StatusClass(logRecord.Status); ok {
+		bucket.statusClasses[class]++
+	}
+
+	if isLatencyKnown {
+		if bucket.latencies == nil {
+			bucket.latencies = a.config.newSketch()
+		}
+
+		bucket.latencies.Add(latency)
+	}
+}
+
+// bucketStart возвращает начало интервала времени длительностью size, которому принадлежит moment,
+// в часовом поясе loc. Интервалы выравниваются по полуночи loc, nil loc соответствует UTC.
+func bucketStart(moment time.Time, size time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	moment = moment.In(loc)
+	_, offset := moment.Zone()
+	shift := time.Duration(offset) * time.Second
+
+	return moment.Add(shift).Truncate(size).Add(-shift)
+}
+
+// getLatency возвращает время обработки запроса в секундах, если оно известно.
+// Если время обработки запроса не записано, используется суммарное время ответа upstream-серверов.
+func getLatency(record *log.Record) (float64, bool) {
+	if record.RequestTime != nil {
+		return *record.RequestTime, true
+	}
+
+	if len(record.UpstreamResponseTime) == 0 {
+		return 0, false
+	}
+
+	latency := 0.0
+
+	for _, upstreamTime := range record.UpstreamResponseTime {
+		latency += upstreamTime
+	}
+
+	return latency, true
+}
+
+// addToStatisticsFromMalformedLine учитывает строку line с номером number файла path, которую не удалось распарсить
+// из-за parseErr, в st. В режиме OnErrorQuarantine добавляет строку в строки карантина st.
+func (a *Analyzer) addToStatisticsFromMalformedLine(st *statistics, path string, number int, line string, parseErr error) {
+	if st.malformed[path] == nil {
+		st.malformed[path] = make(map[string]int)
+	}
+
+	st.malformed[path][classifyParseError(parseErr)]++
+
+	if a.config.OnError == OnErrorQuarantine {
+		fmt.Fprintf(&st.quarantine, "%s:%d: %s\n", path, number, line)
+	}
+}
+
+// classifyParseError возвращает вид ошибки разбора строки лога, в который разворачивается err.
+func classifyParseError(err error) string {
+	switch {
+	case errors.Is(err, logparser.ErrMalformedFormat):
+		return report.MalformedFormat
+	case errors.Is(err, logparser.ErrMalformedRequest):
+		return report.MalformedRequest
+	case errors.Is(err, logparser.ErrMalformedTime):
+		return report.MalformedTime
+	case errors.Is(err, logparser.ErrMalformedStatus):
+		return report.MalformedStatus
+	case errors.Is(err, logparser.ErrMalformedBodyBytesSent):
+		return report.MalformedBodyBytesSent
+	default:
+		return report.MalformedOther
+	}
+}
+
+// check проверяет, соответствует ли запись лога отрезку времени анализа и фильтру.
+func (a *Analyzer) check(record *log.Record) bool {
 	isTimeSuccessful := true
 	isFilterSuccessful := true
 
@@ -198,13 +494,10 @@ func (a *Analyzer) check(record *log.Record) (bool, error) {
 	}
 
 	if a.isFilterSpecified {
-		isFilterSuccessful, err = checkFilter(record, a.field, a.value)
-		if err != nil {
-			return false, fmt.Errorf("can`t check filter: %w", err)
-		}
+		isFilterSuccessful = a.filter.Match(record)
 	}
 
-	return isTimeSuccessful && isFilterSuccessful, nil
+	return isTimeSuccessful && isFilterSuccessful
 }
 
 // CheckTime проверяет, лежит ли current время в [from, to].
@@ -222,38 +515,6 @@ func CheckTime(current, from, to time.Time, isFromSpecified, isToSpecified bool)
 	}
 }
 
-// checkFilter проверяет, соответствует ли запись лога фильтруемому полю и его значению.
-func checkFilter(record *log.Record, field, value string) (bool, error) {
-	var current string
-
-	switch field {
-	case "remote_add":
-		current = record.RemoteAddr
-	case "remote_user":
-		current = record.RemoteUser
-	case "time_local":
-		current = record.TimeLocal.String()
-	case "method":
-		current = record.Request.Method
-	case "resource":
-		current = record.Request.Resource
-	case "protocol":
-		current = record.Request.Protocol
-	case "status":
-		current = strconv.Itoa(record.Status)
-	case "body_bytes_sent":
-		current = strconv.Itoa(record.BodyBytesSent)
-	case "http_referer":
-		current = record.HTTPRefer
-	case "http_user_agent":
-		current = record.HTTPUserAgent
-	default:
-		return false, ErrUnknownField{field}
-	}
-
-	return regexp.MustCompile(value).MatchString(current), nil
-}
-
 // generateReport формирует готовый для разметки отчёт из полученного экземпляра statistics.
 func generateReport(st *statistics) (report.Report, error) {
 	var (
@@ -261,19 +522,18 @@ func generateReport(st *statistics) (report.Report, error) {
 		err        error
 	)
 
-	if len(st.responseSizes) != 0 {
-		percentile, err = stats.Percentile(st.responseSizes, 95) // Считаем 95%-ый перцентиль.
+	if st.responseSizes.Count() != 0 {
+		percentile, err = st.responseSizes.Percentile(95) // Считаем 95%-ый перцентиль.
 		if err != nil {
 			return report.Report{}, fmt.Errorf("can`t calculate 95th percentile of the server response size: %w", err)
 		}
 	}
 
-	return report.New(
+	rep := report.New(
 		st.files,
 		st.from,
 		st.to,
-		st.field,
-		st.value,
+		st.filter,
 		st.requestsCount,
 		st.resources,
 		st.codes,
@@ -281,5 +541,127 @@ func generateReport(st *statistics) (report.Report, error) {
 		st.agents,
 		float64(st.totalResponseSize)/float64(st.requestsCount),
 		percentile,
-	), nil
+	)
+
+	rep.FileFormats = st.formats
+	rep.UnrecognizedFiles = st.unrecognized
+	rep.MalformedLines = generateMalformedLines(st)
+	rep.BucketSize = st.bucketSize
+
+	rep.Buckets, err = generateBuckets(st)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("can`t generate buckets: %w", err)
+	}
+
+	if st.latencies.Count() == 0 {
+		return rep, nil
+	}
+
+	rep.Latency, err = generateLatency(st.latencies)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("can`t generate latency: %w", err)
+	}
+
+	for _, resource := range rep.MostFrequentResources {
+		latencies, ok := st.resourceLatencies[resource.Data]
+		if !ok {
+			continue
+		}
+
+		latency, err := generateLatency(latencies)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("can`t generate latency of resource %s: %w", resource.Data, err)
+		}
+
+		rep.ResourceLatencies = append(rep.ResourceLatencies, report.ResourceLatency{
+			Resource: resource.Data,
+			Latency:  latency,
+		})
+	}
+
+	return rep, nil
+}
+
+// generateLatency формирует статистику времени обработки запросов по непустому quantile.Sketch latencies.
+func generateLatency(latencies *quantile.Sketch) (report.Latency, error) {
+	latency := report.Latency{
+		Count:       latencies.Count(),
+		Min:         latencies.Min(),
+		Average:     latencies.Mean(),
+		Max:         latencies.Max(),
+		Percentiles: make([]float64, len(report.LatencyPercentiles)),
+	}
+
+	for i, rank := range report.LatencyPercentiles {
+		percentile, err := latencies.Percentile(rank)
+		if err != nil {
+			return report.Latency{}, fmt.Errorf("can`t calculate %gth percentile of the request time: %w", rank, err)
+		}
+
+		latency.Percentiles[i] = percentile
+	}
+
+	return latency, nil
+}
+
+// generateBuckets формирует статистику непустых интервалов времени в порядке их начала.
+func generateBuckets(st *statistics) ([]report.Bucket, error) {
+	starts := make([]int64, 0, len(st.buckets))
+
+	for start := range st.buckets {
+		starts = append(starts, start)
+	}
+
+	slices.Sort(starts)
+
+	var buckets []report.Bucket
+
+	for _, start := range starts {
+		bucket := st.buckets[start]
+
+		generated := report.Bucket{
+			Start:         bucket.start,
+			RequestsCount: bucket.requestsCount,
+			StatusClasses: bucket.statusClasses,
+			BytesSent:     bucket.bytesSent,
+		}
+
+		if bucket.latencies != nil {
+			latency, err := generateLatency(bucket.latencies)
+			if err != nil {
+				return nil, fmt.Errorf("can`t generate latency of bucket %s: %w", bucket.start, err)
+			}
+
+			generated.Latency = latency
+		}
+
+		buckets = append(buckets, generated)
+	}
+
+	return buckets, nil
+}
+
+// generateMalformedLines формирует список количеств некорректных строк в порядке файлов и видов ошибок.
+func generateMalformedLines(st *statistics) []report.MalformedLines {
+	var malformed []report.MalformedLines
+
+	for _, file := range st.files {
+		kinds := make([]string, 0, len(st.malformed[file]))
+
+		for kind := range st.malformed[file] {
+			kinds = append(kinds, kind)
+		}
+
+		sort.Strings(kinds)
+
+		for _, kind := range kinds {
+			malformed = append(malformed, report.MalformedLines{
+				File:  file,
+				Kind:  kind,
+				Count: st.malformed[file][kind],
+			})
+		}
+	}
+
+	return malformed
 }
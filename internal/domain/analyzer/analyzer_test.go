@@ -1,35 +1,50 @@
 package analyzer_test
 
 import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/analyzer"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/finder"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/loader"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/quantile"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/montanaflynn/stats"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// workerCounts - количества одновременно обрабатываемых файлов, с которыми проверяется анализ.
+// Отчёты последовательной и параллельной обработки должны совпадать.
+var workerCounts = []int{1, 4}
+
+// baseDir - директория тестовых логов относительно директории пакета.
+const baseDir = "../../infrastructure"
+
 func TestAnalyze(t *testing.T) {
-	f := finder.Finder{}
+	f := finder.Finder{BaseDir: baseDir}
 
-	patternPaths, patternIsLocal, _ := f.Find(`logs/*`)
+	patternPaths, patternIsLocal, _ := f.Find([]string{`logs/*`})
 
-	urlPath, urlIsLocal, _ := f.Find(
+	urlPath, urlIsLocal, _ := f.Find([]string{
 		`https://raw.githubusercontent.com/elastic/examples/master/Common%20Data%20Formats/nginx_logs/nginx_logs`,
-	)
+	})
 
-	localPath1, isLocal1, _ := f.Find(`logs/2024-11-07`)
+	localPath1, isLocal1, _ := f.Find([]string{`logs/2024-11-07`})
 
-	localPath2, isLocal2, _ := f.Find(`logs/2024-11-08`)
+	localPath2, isLocal2, _ := f.Find([]string{`logs/2024-11-08`})
 
 	type args struct {
 		from              time.Time
 		to                time.Time
-		field             string
-		value             string
+		filter            string
 		read              int
 		isFromSpecified   bool
 		isToSpecified     bool
@@ -48,8 +63,7 @@ func TestAnalyze(t *testing.T) {
 			args: args{
 				read:    4,
 				paths:   patternPaths,
-				field:   "-",
-				value:   "-",
+				filter:  "-",
 				isLocal: patternIsLocal,
 			},
 			wantRep: report.New(
@@ -57,7 +71,6 @@ func TestAnalyze(t *testing.T) {
 				"-",
 				"-",
 				"-",
-				"-",
 				8,
 				map[string]int{
 					"/Organized-open%20system/intranet.jpg":           1,
@@ -102,8 +115,7 @@ func TestAnalyze(t *testing.T) {
 			args: args{
 				read:    10,
 				paths:   urlPath,
-				field:   "-",
-				value:   "-",
+				filter:  "-",
 				isLocal: urlIsLocal,
 			},
 			wantRep: report.New(
@@ -111,7 +123,6 @@ func TestAnalyze(t *testing.T) {
 				"-",
 				"-",
 				"-",
-				"-",
 				10,
 				map[string]int{
 					"/downloads/product_1": 8,
@@ -142,15 +153,13 @@ func TestAnalyze(t *testing.T) {
 				read:            10,
 				paths:           localPath1,
 				from:            time.Date(2024, 11, 7, 16, 7, 56, 0, time.FixedZone("+0000", 0)),
-				field:           "-",
-				value:           "-",
+				filter:          "-",
 				isLocal:         isLocal2,
 				isFromSpecified: true,
 			},
 			wantRep: report.New(
 				localPath1,
-				"2024-11-07 16:07:56 +0000 +0000",
-				"-",
+				"2024-11-07T16:07:56Z",
 				"-",
 				"-",
 				10,
@@ -205,16 +214,14 @@ func TestAnalyze(t *testing.T) {
 				read:          10,
 				paths:         localPath1,
 				to:            time.Date(2024, 11, 7, 16, 7, 56, 0, time.FixedZone("+0000", 0)),
-				field:         "-",
-				value:         "-",
+				filter:        "-",
 				isLocal:       isLocal1,
 				isToSpecified: true,
 			},
 			wantRep: report.New(
 				localPath1,
 				"-",
-				"2024-11-07 16:07:56 +0000 +0000",
-				"-",
+				"2024-11-07T16:07:56Z",
 				"-",
 				10,
 				map[string]int{
@@ -270,17 +277,15 @@ func TestAnalyze(t *testing.T) {
 				paths:           localPath2,
 				from:            time.Date(2024, 11, 8, 14, 39, 44, 0, time.FixedZone("+0000", 0)),
 				to:              time.Date(2024, 11, 8, 14, 40, 3, 0, time.FixedZone("+0000", 0)),
-				field:           "-",
-				value:           "-",
+				filter:          "-",
 				isLocal:         isLocal2,
 				isFromSpecified: true,
 				isToSpecified:   true,
 			},
 			wantRep: report.New(
 				localPath2,
-				"2024-11-08 14:39:44 +0000 +0000",
-				"2024-11-08 14:40:03 +0000 +0000",
-				"-",
+				"2024-11-08T14:39:44Z",
+				"2024-11-08T14:40:03Z",
 				"-",
 				10,
 				map[string]int{
@@ -335,8 +340,7 @@ func TestAnalyze(t *testing.T) {
 			args: args{
 				read:              10,
 				paths:             localPath2,
-				field:             "http_user_agent",
-				value:             "Opera*",
+				filter:            `http_user_agent =~ "Opera*"`,
 				isLocal:           isLocal2,
 				isFilterSpecified: true,
 			},
@@ -344,8 +348,7 @@ func TestAnalyze(t *testing.T) {
 				localPath2,
 				"-",
 				"-",
-				"http_user_agent",
-				"Opera*",
+				`http_user_agent =~ "Opera*"`,
 				10,
 				map[string]int{
 					"/Multi-layered/responsive/disintermediate/task-force-Triple-buffered.gif":   1,
@@ -392,25 +395,26 @@ func TestAnalyze(t *testing.T) {
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			a := analyzer.New(&loader.Loader{}, &parser.Parser{})
-
-			gotRep, err := a.Analyze(
-				tt.args.from,
-				tt.args.to,
-				tt.args.field,
-				tt.args.value,
-				tt.args.read,
-				tt.args.isFromSpecified,
-				tt.args.isToSpecified,
-				tt.args.isFilterSpecified,
-				tt.args.paths,
-				tt.args.isLocal)
-
-			assert.Equal(t, tt.wantRep, gotRep)
-			assert.NoError(t, err)
-		})
+	for _, workers := range workerCounts {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s with %d workers", tt.name, workers), func(t *testing.T) {
+				a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{Workers: workers})
+
+				gotRep, err := a.Analyze(
+					tt.args.from,
+					tt.args.to,
+					tt.args.filter,
+					tt.args.read,
+					tt.args.isFromSpecified,
+					tt.args.isToSpecified,
+					tt.args.isFilterSpecified,
+					tt.args.paths,
+					tt.args.isLocal)
+
+				assert.Equal(t, tt.wantRep, gotRep)
+				assert.NoError(t, err)
+			})
+		}
 	}
 }
 
@@ -554,3 +558,514 @@ func TestCheckTime(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeMalformedLines(t *testing.T) {
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /logistical.svg HTTP/1.1" 200 1373 "-" "Opera/8.62"`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /logistical.svg HTTP/1.1" 200 13`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "\x16\x03\x01" 400 150 "-" "-"`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 2O0 1373 "-" "Opera/8.62"`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62"`,
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		onError        string
+		wantMalformed  []report.MalformedLines
+		wantQuarantine string
+		wantErr        bool
+	}{
+		{
+			name:    "fail",
+			onError: analyzer.OnErrorFail,
+			wantErr: true,
+		},
+		{
+			name:    "skip",
+			onError: analyzer.OnErrorSkip,
+			wantMalformed: []report.MalformedLines{
+				{File: path, Kind: report.MalformedFormat, Count: 1},
+				{File: path, Kind: report.MalformedRequest, Count: 1},
+				{File: path, Kind: report.MalformedStatus, Count: 1},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "quarantine",
+			onError: analyzer.OnErrorQuarantine,
+			wantMalformed: []report.MalformedLines{
+				{File: path, Kind: report.MalformedFormat, Count: 1},
+				{File: path, Kind: report.MalformedRequest, Count: 1},
+				{File: path, Kind: report.MalformedStatus, Count: 1},
+			},
+			wantQuarantine: path + ":2: " + lines[1] + "\n" + path + ":3: " + lines[2] + "\n" + path + ":4: " + lines[3] + "\n",
+			wantErr:        false,
+		},
+	}
+
+	for _, workers := range workerCounts {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s with %d workers", tt.name, workers), func(t *testing.T) {
+				var quarantine strings.Builder
+
+				a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{
+					OnError:    tt.onError,
+					Quarantine: &quarantine,
+					Workers:    workers,
+				})
+
+				gotRep, err := a.Analyze(time.Time{}, time.Time{}, "-", 10, false, false, false, []string{path}, true)
+
+				assert.Equal(t, tt.wantErr, err != nil)
+
+				if !tt.wantErr {
+					assert.Equal(t, 2, gotRep.RequestsCount)
+					assert.Equal(t, tt.wantMalformed, gotRep.MalformedLines)
+				}
+
+				assert.Equal(t, tt.wantQuarantine, quarantine.String())
+			})
+		}
+	}
+}
+
+func TestAnalyzeWorkers(t *testing.T) {
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62" 0.100`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 13`,
+		`81.9.10.11 - - [07/Nov/2024:16:07:56 +0000] "GET /logistical.svg HTTP/1.1" 404 250 "-" "curl/8.0" 0.300`,
+		`81.9.10.11 - - [07/Nov/2024:16:07:57 +0000] "POST /api HTTP/1.1" 500 75 "-" "curl/8.0" 1.700`,
+	}
+
+	ps, err := parser.New(parser.CombinedFormat + " $request_time")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Файлы с разным количеством строк, чтобы их обработка завершалась в разном порядке.
+	paths := make([]string, 0, 8)
+
+	for i := range cap(paths) {
+		var data strings.Builder
+
+		for j := range (i%3 + 1) * 500 {
+			data.WriteString(lines[(i+j)%len(lines)])
+			data.WriteString("\n")
+		}
+
+		path := filepath.Join(t.TempDir(), fmt.Sprintf("logs%d.txt", i))
+
+		err := os.WriteFile(path, []byte(data.String()), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	analyze := func(workers int) (report.Report, string) {
+		var quarantine strings.Builder
+
+		a := analyzer.New(&loader.Loader{}, ps, analyzer.Config{
+			OnError:    analyzer.OnErrorQuarantine,
+			Quarantine: &quarantine,
+			Workers:    workers,
+			BucketSize: time.Second,
+		})
+
+		rep, err := a.Analyze(time.Time{}, time.Time{}, "-", math.MaxInt, false, false, false, paths, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return rep, quarantine.String()
+	}
+
+	wantRep, wantQuarantine := analyze(1)
+
+	for _, workers := range []int{2, 3, 8, 16} {
+		gotRep, gotQuarantine := analyze(workers)
+
+		assert.Equal(t, wantRep, gotRep)
+		assert.Equal(t, wantQuarantine, gotQuarantine)
+	}
+}
+
+func TestAnalyzeLatency(t *testing.T) {
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62" 0.100 -`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62" 0.300 0.100, 0.150`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /logistical.svg HTTP/1.1" 200 1373 "-" "Opera/8.62" - 0.200`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /health HTTP/1.1" 200 2 "-" "Opera/8.62" - -`,
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := parser.New(parser.CombinedFormat + " $request_time $upstream_response_time")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := analyzer.New(&loader.Loader{}, ps, analyzer.Config{})
+
+	gotRep, err := a.Analyze(time.Time{}, time.Time{}, "-", 10, false, false, false, []string{path}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, gotRep.Latency.Count)
+	assert.InDelta(t, 0.1, gotRep.Latency.Min, 1e-9)
+	assert.InDelta(t, 0.2, gotRep.Latency.Average, 1e-9)
+	assert.InDelta(t, 0.3, gotRep.Latency.Max, 1e-9)
+	assert.Len(t, gotRep.Latency.Percentiles, len(report.LatencyPercentiles))
+	assert.InDelta(t, 0.15, gotRep.Latency.Percentiles[0], 1e-9)
+
+	assert.Len(t, gotRep.ResourceLatencies, 2)
+	assert.Equal(t, "/core.svg", gotRep.ResourceLatencies[0].Resource)
+	assert.Equal(t, 2, gotRep.ResourceLatencies[0].Latency.Count)
+	assert.Equal(t, "/logistical.svg", gotRep.ResourceLatencies[1].Resource)
+	assert.InDelta(t, 0.2, gotRep.ResourceLatencies[1].Latency.Max, 1e-9)
+}
+
+func TestAnalyzeResourceLatenciesLimit(t *testing.T) {
+	const format = `70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET %s HTTP/1.1" 200 1373 "-" "Opera/8.62" %s`
+
+	var data strings.Builder
+
+	// Частые ресурсы перемежаются редкими, каждый из которых запрашивается один раз.
+	for i := range 100 {
+		for _, resource := range []string{"/a", "/b", "/c"} {
+			fmt.Fprintf(&data, format+"\n", resource, "0.100")
+		}
+
+		fmt.Fprintf(&data, format+"\n", fmt.Sprintf("/item%d", i), "0.500")
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	err := os.WriteFile(path, []byte(data.String()), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := parser.New(parser.CombinedFormat + " $request_time")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := analyzer.New(&loader.Loader{}, ps, analyzer.Config{ResourceLatencies: 3})
+
+	gotRep, err := a.Analyze(time.Time{}, time.Time{}, "-", math.MaxInt, false, false, false, []string{path}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 400, gotRep.Latency.Count)
+	require.GreaterOrEqual(t, len(gotRep.ResourceLatencies), 3)
+
+	for i, resource := range []string{"/a", "/b", "/c"} {
+		assert.Equal(t, resource, gotRep.ResourceLatencies[i].Resource)
+		assert.Equal(t, 100, gotRep.ResourceLatencies[i].Latency.Count)
+		assert.InDelta(t, 0.1, gotRep.ResourceLatencies[i].Latency.Max, 1e-9)
+	}
+}
+
+func TestAnalyzePercentileEstimate(t *testing.T) {
+	f := finder.Finder{BaseDir: baseDir}
+
+	paths, isLocal, err := f.Find([]string{`logs/*`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Точный перцентиль размеров ответов всех строк логов.
+	var responseSizes []float64
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			record, err := (&parser.Parser{}).Parse(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			responseSizes = append(responseSizes, float64(record.BodyBytesSent))
+		}
+	}
+
+	want, err := stats.Percentile(responseSizes, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      analyzer.Config
+		accuracy float64
+	}{
+		{
+			name:     "exact",
+			cfg:      analyzer.Config{ExactPercentiles: true},
+			accuracy: 0,
+		},
+		{
+			name:     "default accuracy",
+			cfg:      analyzer.Config{},
+			accuracy: quantile.DefaultAccuracy,
+		},
+		{
+			name:     "low accuracy",
+			cfg:      analyzer.Config{PercentileAccuracy: 0.1},
+			accuracy: 0.1,
+		},
+	}
+
+	for _, workers := range workerCounts {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s with %d workers", tt.name, workers), func(t *testing.T) {
+				tt.cfg.Workers = workers
+
+				a := analyzer.New(&loader.Loader{}, &parser.Parser{}, tt.cfg)
+
+				gotRep, err := a.Analyze(time.Time{}, time.Time{}, "-", math.MaxInt, false, false, false, paths, isLocal)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, len(responseSizes), gotRep.RequestsCount)
+				assert.InDelta(t, want, gotRep.Percentile95ResponseSize, want*tt.accuracy)
+			})
+		}
+	}
+}
+
+func TestAnalyzeFilter(t *testing.T) {
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /health HTTP/1.1" 200 10 "-" "curl/8.5.0"`,
+		`70.27.134.194 - - [07/Nov/2024:16:07:56 +0000] "POST /api/v1/users HTTP/1.1" 502 1373 "-" "Opera/8.62"`,
+		`10.0.3.7 - - [07/Nov/2024:16:07:57 +0000] "POST /health HTTP/1.1" 503 150 "-" "Opera/8.62"`,
+		`10.0.3.8 - - [07/Nov/2024:16:07:58 +0000] "GET /core.svg HTTP/1.1" 404 90 "-" "Opera/8.62"`,
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		filter    string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "numeric comparison with logical operators",
+			filter:    `status >= 500 && method == "POST" && !(resource =~ "^/health")`,
+			wantCount: 1,
+		},
+		{
+			name:      "cidr",
+			filter:    `remote_add in 10.0.0.0/8 || body_bytes_sent < 50`,
+			wantCount: 3,
+		},
+		{
+			name:    "invalid filter",
+			filter:  `status >= "5xx"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{})
+
+			gotRep, err := a.Analyze(time.Time{}, time.Time{}, tt.filter, math.MaxInt, false, false, true, []string{path}, true)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "5xx is not a valid number to compare with status")
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantCount, gotRep.RequestsCount)
+			assert.Equal(t, tt.filter, gotRep.Filter)
+		})
+	}
+}
+
+// recordingExporter запоминает строки экспортированных записей.
+type recordingExporter struct {
+	lines []string
+}
+
+func (e *recordingExporter) Export(line string, _ *log.Record) error {
+	e.lines = append(e.lines, line)
+
+	return nil
+}
+
+func TestAnalyzeExport(t *testing.T) {
+	const read = 50
+
+	paths := make([]string, 0, 3)
+
+	for i := range cap(paths) {
+		path := filepath.Join(t.TempDir(), fmt.Sprintf("logs%d.txt", i))
+
+		writeFixture(t, path, int64(i+1)<<16, 97)
+
+		paths = append(paths, path)
+	}
+
+	var want []string
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fileLines := 0
+
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.Contains(line, `" 404 `) && fileLines < read {
+				want = append(want, line)
+				fileLines++
+			}
+		}
+	}
+
+	for _, parseWorkers := range []int{1, 3} {
+		t.Run(fmt.Sprintf("%d parse workers", parseWorkers), func(t *testing.T) {
+			exp := &recordingExporter{}
+
+			a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{
+				OnError:      analyzer.OnErrorSkip,
+				Workers:      4,
+				ParseWorkers: parseWorkers,
+				Exporter:     exp,
+			})
+
+			rep, err := a.Analyze(time.Time{}, time.Time{}, "status == 404", read, false, false, true, paths, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, want, exp.lines)
+			assert.Equal(t, len(want), rep.RequestsCount)
+		})
+	}
+}
+
+func TestAnalyzeBuckets(t *testing.T) {
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:20:59:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62" 0.100`,
+		`70.27.134.194 - - [07/Nov/2024:21:00:00 +0000] "GET /core.svg HTTP/1.1" 503 10 "-" "Opera/8.62" -`,
+		`70.27.134.194 - - [08/Nov/2024:00:59:59 +0300] "GET /core.svg HTTP/1.1" 404 20 "-" "Opera/8.62" 0.300`,
+		`70.27.134.194 - - [07/Nov/2024:21:30:00 +0000] "GET /core.svg HTTP/1.1" 999 30 "-" "Opera/8.62" -`,
+	}
+
+	ps, err := parser.New(parser.CombinedFormat + " $request_time")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name string
+		cfg  analyzer.Config
+		want []report.Bucket
+	}{
+		{
+			name: "hours",
+			cfg:  analyzer.Config{BucketSize: time.Hour},
+			want: []report.Bucket{
+				{
+					Start:         time.Date(2024, time.November, 7, 20, 0, 0, 0, time.UTC),
+					RequestsCount: 1,
+					StatusClasses: []int{0, 1, 0, 0, 0},
+					BytesSent:     1373,
+					Latency: report.Latency{
+						Count: 1, Min: 0.1, Average: 0.1, Max: 0.1, Percentiles: []float64{0.1, 0.1, 0.1, 0.1, 0.1},
+					},
+				},
+				{
+					Start:         time.Date(2024, time.November, 7, 21, 0, 0, 0, time.UTC),
+					RequestsCount: 3,
+					StatusClasses: []int{0, 0, 0, 1, 1},
+					BytesSent:     60,
+					Latency: report.Latency{
+						Count: 1, Min: 0.3, Average: 0.3, Max: 0.3, Percentiles: []float64{0.3, 0.3, 0.3, 0.3, 0.3},
+					},
+				},
+			},
+		},
+		{
+			name: "days in time zone",
+			cfg:  analyzer.Config{BucketSize: 24 * time.Hour, Location: moscow},
+			want: []report.Bucket{
+				{
+					Start:         time.Date(2024, time.November, 7, 0, 0, 0, 0, moscow),
+					RequestsCount: 1,
+					StatusClasses: []int{0, 1, 0, 0, 0},
+					BytesSent:     1373,
+					Latency: report.Latency{
+						Count: 1, Min: 0.1, Average: 0.1, Max: 0.1, Percentiles: []float64{0.1, 0.1, 0.1, 0.1, 0.1},
+					},
+				},
+				{
+					Start:         time.Date(2024, time.November, 8, 0, 0, 0, 0, moscow),
+					RequestsCount: 3,
+					StatusClasses: []int{0, 0, 0, 1, 1},
+					BytesSent:     60,
+					Latency: report.Latency{
+						Count: 1, Min: 0.3, Average: 0.3, Max: 0.3, Percentiles: []float64{0.3, 0.3, 0.3, 0.3, 0.3},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := analyzer.New(&loader.Loader{}, ps, tt.cfg)
+
+			rep, err := a.Analyze(time.Time{}, time.Time{}, "-", math.MaxInt, false, false, false, []string{path}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.cfg.BucketSize, rep.BucketSize)
+			assert.Equal(t, tt.want, rep.Buckets)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+)
+
+const batchSize = 1024 // Количество строк, которые парсятся одной горутиной конвейера за раз.
+
+// parsedLine хранит результат парсинга и проверки строки лога.
+type parsedLine struct {
+	number   int         // Номер строки в файле, начиная с 1.
+	text     string      // Строка лога, если её не удалось распарсить или она содержит запись, удовлетворяющую условиям.
+	record   *log.Record // Запись лога, если строка распарсена и удовлетворяет условиям.
+	parseErr error       // Ошибка парсинга строки.
+}
+
+// lineBatch - последовательные строки лога, первая из которых имеет номер first.
+type lineBatch struct {
+	index int // Порядковый номер пачки в файле, начиная с 0.
+	first int
+	lines []string
+}
+
+// parsedBatch - результаты парсинга строк пачки с порядковым номером index.
+type parsedBatch struct {
+	index int
+	lines []parsedLine
+}
+
+// parseLine парсит строку text с номером number с помощью parse и проверяет полученную запись на соответствие условиям.
+func (a *Analyzer) parseLine(parse func(lg string) (*log.Record, error), number int, text string) parsedLine {
+	record, err := parse(text)
+	if err != nil {
+		return parsedLine{number: number, text: text, parseErr: err}
+	}
+
+	if record == nil { // Служебная строка лога, не содержащая записи.
+		return parsedLine{number: number}
+	}
+
+	if !a.check(record) {
+		return parsedLine{number: number}
+	}
+
+	return parsedLine{number: number, text: text, record: record}
+}
+
+// addToStatisticsFromParsedLine добавляет результат разбора строки line файла path в st.
+// Запись, удовлетворяющая условиям, передаётся config.Exporter, если он задан.
+// Возвращает true, если строка содержит запись, удовлетворяющую условиям.
+func (a *Analyzer) addToStatisticsFromParsedLine(st *statistics, path string, line *parsedLine) (bool, error) {
+	switch {
+	case line.parseErr != nil && a.config.OnError != OnErrorSkip && a.config.OnError != OnErrorQuarantine:
+		return false, fmt.Errorf("can`t parse scan result: %w", line.parseErr)
+	case line.parseErr != nil:
+		a.addToStatisticsFromMalformedLine(st, path, line.number, line.text, line.parseErr)
+
+		return false, nil
+	case line.record == nil:
+		return false, nil
+	}
+
+	a.addToStatisticsFromLogRecord(st, line.record)
+
+	if a.config.Exporter != nil {
+		err := a.config.Exporter.Export(line.text, line.record)
+		if err != nil {
+			return false, fmt.Errorf("can`t export record: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// addToStatisticsFromLogConcurrently анализирует lg файла path так же, как addToStatisticsFromLog,
+// но парсит строки в workers горутинах. Чтение строк пачками, их парсинг и добавление результатов в st
+// образуют конвейер: результаты добавляются в порядке строк файла, поэтому ограничение read, карантин
+// и возвращаемая ошибка совпадают с последовательным анализом. parse должна допускать одновременный вызов.
+func (a *Analyzer) addToStatisticsFromLogConcurrently(
+	st *statistics, path string, lg io.Reader, parse func(lg string) (*log.Record, error), workers int,
+) error {
+	done := make(chan struct{})                // Закрывается, когда результаты больше не нужны.
+	tokens := make(chan struct{}, 2*workers)   // Ограничивает количество прочитанных, но не добавленных в st пачек.
+	batches := make(chan lineBatch, workers)   // Прочитанные пачки строк.
+	results := make(chan parsedBatch, workers) // Распарсенные пачки строк в порядке завершения парсинга.
+
+	var (
+		readErr error
+		reader  sync.WaitGroup
+		parsers sync.WaitGroup
+	)
+
+	reader.Add(1)
+
+	go func() {
+		defer reader.Done()
+		defer close(batches)
+
+		readErr = readBatches(lg, batches, tokens, done)
+	}()
+
+	for range workers {
+		parsers.Add(1)
+
+		go func() {
+			defer parsers.Done()
+
+			a.parseBatches(parse, batches, results, done)
+		}()
+	}
+
+	go func() {
+		parsers.Wait()
+		close(results)
+	}()
+
+	stop := sync.OnceFunc(func() {
+		close(done)
+		reader.Wait()
+		parsers.Wait()
+	})
+	defer stop()
+
+	err := a.addToStatisticsFromBatches(st, path, results, tokens)
+	if err != nil {
+		return err
+	}
+
+	// Добавление могло прекратиться после read записей до завершения чтения,
+	// поэтому ошибка чтения проверяется только после остановки конвейера.
+	stop()
+
+	return readErr
+}
+
+// parseBatches парсит строки пачек из batches с помощью parse и отправляет результаты в results,
+// пока batches не закрыт или не закрыт done.
+func (a *Analyzer) parseBatches(
+	parse func(lg string) (*log.Record, error), batches <-chan lineBatch, results chan<- parsedBatch, done <-chan struct{},
+) {
+	for batch := range batches {
+		parsed := parsedBatch{index: batch.index, lines: make([]parsedLine, len(batch.lines))}
+
+		for i, text := range batch.lines {
+			parsed.lines[i] = a.parseLine(parse, batch.first+i, text)
+		}
+
+		select {
+		case results <- parsed:
+		case <-done:
+			return
+		}
+	}
+}
+
+// addToStatisticsFromBatches добавляет результаты парсинга пачек из results в st в порядке строк файла path,
+// освобождая место в tokens для каждой добавленной пачки. Добавление прекращается после read записей,
+// удовлетворяющих условиям.
+func (a *Analyzer) addToStatisticsFromBatches(st *statistics, path string, results <-chan parsedBatch, tokens <-chan struct{}) error {
+	pending := make(map[int]parsedBatch) // Распарсенные пачки, ожидающие добавления предыдущих.
+	next := 0
+	linesRead := 0
+
+	for batch := range results {
+		pending[batch.index] = batch
+
+		for batch, ok := pending[next]; ok; batch, ok = pending[next] {
+			delete(pending, next)
+			next++
+
+			<-tokens
+
+			for i := range batch.lines {
+				if linesRead >= a.read {
+					return nil
+				}
+
+				isRead, err := a.addToStatisticsFromParsedLine(st, path, &batch.lines[i])
+				if err != nil {
+					return err
+				}
+
+				if isRead {
+					linesRead++
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// readBatches построчно читает lg и отправляет строки пачками по batchSize в batches,
+// занимая место в tokens для каждой пачки. Прекращает чтение, когда закрывается done.
+func readBatches(lg io.Reader, batches chan<- lineBatch, tokens chan<- struct{}, done <-chan struct{}) error {
+	scn := bufio.NewScanner(lg)
+	batch := lineBatch{first: 1, lines: make([]string, 0, batchSize)}
+
+	send := func() bool {
+		select {
+		case tokens <- struct{}{}:
+		case <-done:
+			return false
+		}
+
+		select {
+		case batches <- batch:
+		case <-done:
+			return false
+		}
+
+		batch = lineBatch{index: batch.index + 1, first: batch.first + len(batch.lines), lines: make([]string, 0, batchSize)}
+
+		return true
+	}
+
+	for scn.Scan() {
+		batch.lines = append(batch.lines, scn.Text())
+
+		if len(batch.lines) == batchSize && !send() {
+			return nil
+		}
+	}
+
+	if len(batch.lines) != 0 && !send() {
+		return nil
+	}
+
+	if err := scn.Err(); err != nil {
+		return fmt.Errorf("can`t scan: %w", err)
+	}
+
+	return nil
+}
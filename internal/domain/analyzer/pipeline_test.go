@@ -0,0 +1,154 @@
+package analyzer_test
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/analyzer"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/loader"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// Размеры файла лога, генерируемого для бенчмарков, если флаг fixture-size не задан.
+const (
+	defaultFixtureSize = 2 << 30   // Размер, при котором файл не помещается в кэши и отражает обработку больших логов.
+	shortFixtureSize   = 256 << 20 // Размер в режиме -short, при котором бенчмарк проходит быстрее.
+)
+
+// fixtureSize - размер в байтах файла лога, генерируемого для бенчмарков. Нулевой размер соответствует
+// defaultFixtureSize или, в режиме -short, shortFixtureSize.
+var fixtureSize = flag.Int64("fixture-size", 0, "size in bytes of the log file generated for benchmarks "+
+	"(defaults to 2GiB or 256MiB with -short)")
+
+// benchmarkFixtureSize возвращает размер в байтах файла лога, генерируемого для бенчмарков.
+func benchmarkFixtureSize() int64 {
+	switch {
+	case *fixtureSize > 0:
+		return *fixtureSize
+	case testing.Short():
+		return shortFixtureSize
+	default:
+		return defaultFixtureSize
+	}
+}
+
+// writeFixture записывает в файл path строки лога формата combined общим размером не меньше size байт.
+// Каждая malformedEvery-ая строка не соответствует формату, если malformedEvery больше 0.
+func writeFixture(tb testing.TB, path string, size int64, malformedEvery int) {
+	tb.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	rnd := rand.New(rand.NewSource(1))
+	resources := []string{"/", "/index.html", "/core.svg", "/logistical.svg", "/api/v1/users", "/health"}
+	agents := []string{"curl/8.5.0", "Opera/8.62", "Mozilla/5.0 (X11; Linux x86_64)", "Go-http-client/1.1"}
+	codes := []int{200, 200, 200, 301, 404, 500}
+	start := time.Date(2024, time.November, 7, 16, 0, 0, 0, time.UTC)
+
+	writer := bufio.NewWriter(file)
+
+	for i, written := 0, int64(0); written < size; i++ {
+		var line string
+
+		if malformedEvery > 0 && i%malformedEvery == malformedEvery-1 {
+			line = fmt.Sprintf("70.27.134.%d - - [broken line %d\n", rnd.Intn(256), i)
+		} else {
+			line = fmt.Sprintf("70.27.%d.%d - - [%s] \"GET %s HTTP/1.1\" %d %d \"-\" \"%s\"\n",
+				rnd.Intn(16), rnd.Intn(256), start.Add(time.Duration(i)*time.Second).Format("02/Jan/2006:15:04:05 -0700"),
+				resources[rnd.Intn(len(resources))], codes[rnd.Intn(len(codes))], rnd.Intn(100000),
+				agents[rnd.Intn(len(agents))])
+		}
+
+		n, err := writer.WriteString(line)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		written += int64(n)
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestAnalyzeParseWorkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.txt")
+
+	writeFixture(t, path, 1<<20, 97)
+
+	type result struct {
+		rep        report.Report
+		quarantine string
+		err        error
+	}
+
+	analyze := func(onError string, read, parseWorkers int) result {
+		var quarantine strings.Builder
+
+		a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{
+			OnError:      onError,
+			Quarantine:   &quarantine,
+			ParseWorkers: parseWorkers,
+		})
+
+		rep, err := a.Analyze(time.Time{}, time.Time{}, `status =~ "^2"`, read, false, false, true, []string{path}, true)
+
+		return result{rep: rep, quarantine: quarantine.String(), err: err}
+	}
+
+	for _, onError := range []string{analyzer.OnErrorFail, analyzer.OnErrorQuarantine} {
+		for _, read := range []int{1, 95, 1500, math.MaxInt} {
+			want := analyze(onError, read, 1)
+
+			for _, parseWorkers := range []int{2, 3, 8} {
+				t.Run(fmt.Sprintf("%s read %d with %d parse workers", onError, read, parseWorkers), func(t *testing.T) {
+					got := analyze(onError, read, parseWorkers)
+
+					assert.Equal(t, want.rep, got.rep)
+					assert.Equal(t, want.quarantine, got.quarantine)
+					assert.Equal(t, want.err, got.err)
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkAnalyzeParseWorkers(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "logs.txt")
+
+	size := benchmarkFixtureSize()
+
+	writeFixture(b, path, size, 0)
+
+	for _, parseWorkers := range []int{1, 2, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("%d parse workers", parseWorkers), func(b *testing.B) {
+			b.SetBytes(size)
+
+			for range b.N {
+				a := analyzer.New(&loader.Loader{}, &parser.Parser{}, analyzer.Config{ParseWorkers: parseWorkers})
+
+				_, err := a.Analyze(time.Time{}, time.Time{}, "-", math.MaxInt, false, false, false, []string{path}, true)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/quantile"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// statistics хранит промежуточную статистику и метаданные.
+// Вся необходимая для формирования отчёта информация хранится в этой структуре.
+// В некотором смысле statistics - промежуточное представление отчёта.
+type statistics struct {
+	from              string
+	to                string
+	filter            string
+	requestsCount     int
+	totalResponseSize int
+	files             []string
+	formats           []report.FileFormat
+	unrecognized      []string
+	malformed         map[string]map[string]int   // Количество некорректных строк по файлам и видам ошибок.
+	quarantine        bytes.Buffer                // Строки карантина, ещё не записанные в Config.Quarantine.
+	responseSizes     *quantile.Sketch            // Размеры ответов.
+	latencies         *quantile.Sketch            // Время обработки запросов в секундах.
+	resourceLatencies map[string]*quantile.Sketch // Время обработки запросов в секундах по ресурсам.
+	maxResources      int                         // Количество ресурсов, остающихся в resourceLatencies при вытеснении.
+	resources         map[string]int
+	codes             map[int]int
+	clients           map[string]int
+	agents            map[string]int
+	bucketSize        time.Duration
+	buckets           map[int64]*bucketStatistics // Статистика по интервалам времени, ключ - начало интервала в наносекундах.
+}
+
+// bucketStatistics хранит промежуточную статистику запросов интервала времени, начинающегося в start.
+type bucketStatistics struct {
+	start         time.Time
+	requestsCount int
+	statusClasses []int // Количество запросов по классам кодов ответа в порядке report.StatusClasses.
+	bytesSent     int
+	latencies     *quantile.Sketch // Время обработки запросов в секундах, если известно хотя бы для одного запроса.
+}
+
+// merge добавляет в bucket статистику other того же интервала времени.
+func (bucket *bucketStatistics) merge(other *bucketStatistics) {
+	bucket.requestsCount += other.requestsCount
+	bucket.bytesSent += other.bytesSent
+
+	for class, count := range other.statusClasses {
+		bucket.statusClasses[class] += count
+	}
+
+	switch {
+	case other.latencies == nil:
+	case bucket.latencies == nil:
+		bucket.latencies = other.latencies
+	default:
+		bucket.latencies.Merge(other.latencies)
+	}
+}
+
+// newStatistics возвращает указатель на пустую statistics, перцентили которой оцениваются в соответствии с cfg.
+func newStatistics(cfg *Config) *statistics {
+	maxResources := cfg.ResourceLatencies
+	if maxResources < 1 {
+		maxResources = DefaultResourceLatencies
+	}
+
+	return &statistics{
+		malformed:         make(map[string]map[string]int),
+		responseSizes:     cfg.newSketch(),
+		latencies:         cfg.newSketch(),
+		resourceLatencies: make(map[string]*quantile.Sketch),
+		maxResources:      maxResources,
+		resources:         make(map[string]int),
+		codes:             make(map[int]int),
+		clients:           make(map[string]int),
+		agents:            make(map[string]int),
+		bucketSize:        cfg.BucketSize,
+		buckets:           make(map[int64]*bucketStatistics),
+	}
+}
+
+// merge добавляет в st результаты анализа other, кроме метаданных и строк карантина.
+func (st *statistics) merge(other *statistics) {
+	st.requestsCount += other.requestsCount
+	st.totalResponseSize += other.totalResponseSize
+	st.formats = append(st.formats, other.formats...)
+	st.unrecognized = append(st.unrecognized, other.unrecognized...)
+
+	for file, kinds := range other.malformed {
+		if st.malformed[file] == nil {
+			st.malformed[file] = make(map[string]int)
+		}
+
+		for kind, count := range kinds {
+			st.malformed[file][kind] += count
+		}
+	}
+
+	st.responseSizes.Merge(other.responseSizes)
+	st.latencies.Merge(other.latencies)
+
+	for resource, latencies := range other.resourceLatencies {
+		if st.resourceLatencies[resource] == nil {
+			st.resourceLatencies[resource] = latencies
+
+			continue
+		}
+
+		st.resourceLatencies[resource].Merge(latencies)
+	}
+
+	st.pruneResourceLatencies()
+
+	mergeCounts(st.resources, other.resources)
+	mergeCounts(st.codes, other.codes)
+	mergeCounts(st.clients, other.clients)
+	mergeCounts(st.agents, other.agents)
+
+	for start, bucket := range other.buckets {
+		if st.buckets[start] == nil {
+			st.buckets[start] = bucket
+
+			continue
+		}
+
+		st.buckets[start].merge(bucket)
+	}
+}
+
+// pruneResourceLatencies оставляет в resourceLatencies maxResources ресурсов с наибольшим количеством запросов
+// с известным временем обработки, если количество ресурсов достигло удвоенного maxResources.
+// Вытеснение сразу половины ресурсов ограничивает память, почти не замедляя добавление новых ресурсов.
+func (st *statistics) pruneResourceLatencies() {
+	if len(st.resourceLatencies) < 2*st.maxResources {
+		return
+	}
+
+	resources := make([]string, 0, len(st.resourceLatencies))
+	for resource := range st.resourceLatencies {
+		resources = append(resources, resource)
+	}
+
+	// Ресурсы с равным количеством упорядочиваются по имени, чтобы вытеснение было детерминированным.
+	sort.Slice(resources, func(i, j int) bool {
+		ci, cj := st.resourceLatencies[resources[i]].Count(), st.resourceLatencies[resources[j]].Count()
+		if ci != cj {
+			return ci > cj
+		}
+
+		return resources[i] < resources[j]
+	})
+
+	for _, resource := range resources[st.maxResources:] {
+		delete(st.resourceLatencies, resource)
+	}
+}
+
+// mergeCounts добавляет в dst количества из src.
+func mergeCounts[T string | int](dst, src map[T]int) {
+	for data, count := range src {
+		dst[data] += count
+	}
+}
@@ -0,0 +1,129 @@
+package detector
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+)
+
+// parser описывает интерфейс парсера.
+type parser interface {
+	Parse(lg string) (*log.Record, error) // Parse парсит строку лога в log.Record.
+}
+
+// forker описывает интерфейс парсера, хранящего состояние, которое относится к одному источнику.
+type forker interface {
+	Fork() func(lg string) (*log.Record, error) // Fork возвращает функцию парсинга с собственным состоянием.
+}
+
+// Candidate - формат лога, который может быть выбран Detector, и парсер его строк.
+type Candidate struct {
+	Format string
+	Parser parser
+}
+
+// Detector умеет определять формат лога по его первым строкам.
+type Detector struct {
+	sample     int         // Количество первых строк источника, по которым определяется формат.
+	candidates []Candidate // Форматы-кандидаты в порядке приоритета.
+}
+
+// New возвращает указатель на инициализированный Detector,
+// выбирающий формат из candidates по первым sample строкам источника.
+// При равном количестве распознанных строк предпочтение отдаётся кандидату, указанному раньше.
+func New(sample int, candidates ...Candidate) *Detector {
+	return &Detector{
+		sample:     sample,
+		candidates: candidates,
+	}
+}
+
+// Detect считывает первые строки source и определяет по ним формат лога.
+// Возвращает название формата, функцию парсинга его строк и источник, заново содержащий считанные строки.
+func (d *Detector) Detect(source io.Reader) (format string, parse func(lg string) (*log.Record, error),
+	replay io.Reader, err error,
+) {
+	reader := bufio.NewReader(source)
+
+	var consumed bytes.Buffer
+
+	sample := make([]string, 0, d.sample)
+
+	for len(sample) < d.sample {
+		line, readErr := reader.ReadString('\n')
+		consumed.WriteString(line)
+
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			sample = append(sample, line)
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		} else if readErr != nil {
+			return "", nil, nil, fmt.Errorf("can`t read sample: %w", readErr)
+		}
+	}
+
+	replay = io.MultiReader(&consumed, reader)
+
+	best, bestMatches := -1, 0
+
+	for i, candidate := range d.candidates {
+		matches := 0
+
+		// Парсеры, хранящие состояние, получают отдельную функцию парсинга для каждого источника,
+		// поэтому источники могут обрабатываться одновременно.
+		candidateParse := candidate.Parser.Parse
+		if fk, ok := candidate.Parser.(forker); ok {
+			candidateParse = fk.Fork()
+		}
+
+		// Учитываются только строки, из которых получена запись: служебные строки, например комментарии,
+		// для которых парсер не возвращает ни записи, ни ошибки, не указывают на формат.
+		for _, line := range sample {
+			if record, parseErr := candidateParse(line); parseErr == nil && record != nil {
+				matches++
+			}
+		}
+
+		if matches > bestMatches {
+			best, bestMatches, parse = i, matches, candidateParse
+		}
+	}
+
+	if best == -1 {
+		return "", nil, replay, ErrUnknownFormat{sample}
+	}
+
+	return d.candidates[best].Format, parse, replay, nil
+}
+
+// IsStateful сообщает, хранит ли парсер формата format состояние, относящееся к одному источнику.
+// Строки такого источника должны парситься функцией, возвращённой Detect, последовательно.
+func (d *Detector) IsStateful(format string) bool {
+	for _, candidate := range d.candidates {
+		if candidate.Format == format {
+			_, ok := candidate.Parser.(forker)
+
+			return ok
+		}
+	}
+
+	return false
+}
+
+// Parse парсит строку лога парсером первого кандидата, распознавшего её.
+func (d *Detector) Parse(lg string) (*log.Record, error) {
+	for _, candidate := range d.candidates {
+		if record, err := candidate.Parser.Parse(lg); err == nil {
+			return record, nil
+		}
+	}
+
+	return nil, ErrUnknownFormat{[]string{lg}}
+}
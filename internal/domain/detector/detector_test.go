@@ -0,0 +1,105 @@
+package detector_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/detector"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/apache"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/cloudfront"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/jsonl"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	combinedLog = `244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420 "-" "curl/8.5.0"`
+	commonLog   = `244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420`
+	jsonLog     = `{"remote_addr":"244.103.237.229","time_local":"17/Nov/2024:16:07:52 +0000",` +
+		`"request":"GET /reciprocal.hmtl HTTP/1.1","status":200,"body_bytes_sent":2420}`
+	garbage = "\x00\x01binary garbage"
+)
+
+func TestDetect(t *testing.T) {
+	common, err := apache.New(apache.CommonFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := detector.New(3,
+		detector.Candidate{Format: "nginx", Parser: &parser.Parser{}},
+		detector.Candidate{Format: "common", Parser: common},
+		detector.Candidate{Format: "json", Parser: &jsonl.Parser{}},
+		detector.Candidate{Format: "cloudfront", Parser: &cloudfront.Parser{}},
+	)
+
+	tests := []struct {
+		name       string
+		source     string
+		wantFormat string
+		wantErr    bool
+	}{
+		{
+			name:       "combined log",
+			source:     strings.Repeat(combinedLog+"\n", 5),
+			wantFormat: "nginx",
+			wantErr:    false,
+		},
+		{
+			name:       "common log",
+			source:     strings.Repeat(commonLog+"\n", 5),
+			wantFormat: "common",
+			wantErr:    false,
+		},
+		{
+			name:       "json log with garbage in the sample",
+			source:     garbage + "\n" + jsonLog + "\n" + jsonLog,
+			wantFormat: "json",
+			wantErr:    false,
+		},
+		{
+			name:       "combined log with comments in the sample",
+			source:     "# access log\n# rotated daily\n" + combinedLog + "\n",
+			wantFormat: "nginx",
+			wantErr:    false,
+		},
+		{
+			name:       "comments only",
+			source:     "# access log\n# rotated daily\n",
+			wantFormat: "",
+			wantErr:    true,
+		},
+		{
+			name:       "unknown log",
+			source:     garbage + "\n" + garbage,
+			wantFormat: "",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFormat, _, replay, err := d.Detect(strings.NewReader(tt.source))
+
+			assert.Equal(t, tt.wantFormat, gotFormat)
+			assert.Equal(t, tt.wantErr, err != nil)
+
+			gotSource, err := io.ReadAll(replay)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.source, string(gotSource), "checking that the read lines are replayed")
+		})
+	}
+}
+
+func TestIsStateful(t *testing.T) {
+	d := detector.New(3,
+		detector.Candidate{Format: "nginx", Parser: &parser.Parser{}},
+		detector.Candidate{Format: "cloudfront", Parser: &cloudfront.Parser{}},
+	)
+
+	assert.False(t, d.IsStateful("nginx"))
+	assert.True(t, d.IsStateful("cloudfront"))
+	assert.False(t, d.IsStateful("json"))
+}
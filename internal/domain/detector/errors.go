@@ -0,0 +1,19 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownFormat - ошибка строк лога, не соответствующих ни одному из известных форматов.
+type ErrUnknownFormat struct {
+	sample []string
+}
+
+func (e ErrUnknownFormat) Error() string {
+	if len(e.sample) == 0 {
+		return "empty log does not match any known format"
+	}
+
+	return fmt.Sprintf("%s does not match any known format", strings.Join(e.sample, "\n"))
+}
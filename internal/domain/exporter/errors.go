@@ -0,0 +1,21 @@
+package exporter
+
+import "fmt"
+
+// ErrUnknownFormat - ошибка неизвестного формата экспорта записей.
+type ErrUnknownFormat struct {
+	format string
+}
+
+func (e ErrUnknownFormat) Error() string {
+	return fmt.Sprintf("%s is not a known export format", e.format)
+}
+
+// ErrUnknownColumn - ошибка столбца, не являющегося полем записи лога.
+type ErrUnknownColumn struct {
+	column string
+}
+
+func (e ErrUnknownColumn) Error() string {
+	return fmt.Sprintf("%s is not a known field", e.column)
+}
@@ -0,0 +1,157 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+)
+
+// Форматы экспорта записей лога.
+const (
+	FormatRaw  = "raw"  // Исходные строки лога.
+	FormatJSON = "json" // JSON-объекты столбцов, по одному в строке.
+	FormatCSV  = "csv"  // CSV со строкой заголовка из имён столбцов.
+)
+
+// timeLayout - формат значений полей времени в JSON и CSV.
+const timeLayout = time.RFC3339
+
+// Exporter записывает записи лога в выбранном формате.
+// Записи буферизуются, поэтому после экспорта необходимо вызвать Flush.
+type Exporter struct {
+	format  string
+	columns []string
+	writer  *bufio.Writer
+	csv     *csv.Writer
+}
+
+// New возвращает указатель на Exporter, записывающий записи в w в формате format.
+// columns - имена полей записи, экспортируемых в форматах json и csv, по умолчанию все поля log.FieldNames.
+func New(w io.Writer, format string, columns []string) (*Exporter, error) {
+	if len(columns) == 0 {
+		columns = log.FieldNames()
+	}
+
+	for _, column := range columns {
+		if !log.IsField(column) {
+			return nil, ErrUnknownColumn{column}
+		}
+	}
+
+	e := &Exporter{format: format, columns: columns, writer: bufio.NewWriter(w)}
+
+	switch format {
+	case FormatRaw, FormatJSON:
+	case FormatCSV:
+		e.csv = csv.NewWriter(e.writer)
+
+		err := e.csv.Write(columns)
+		if err != nil {
+			return nil, fmt.Errorf("can`t write csv header: %w", err)
+		}
+	default:
+		return nil, ErrUnknownFormat{format}
+	}
+
+	return e, nil
+}
+
+// Export записывает запись record, полученную из строки лога line.
+func (e *Exporter) Export(line string, record *log.Record) error {
+	switch e.format {
+	case FormatRaw:
+		_, err := e.writer.WriteString(line + "\n")
+		if err != nil {
+			return fmt.Errorf("can`t write line: %w", err)
+		}
+	case FormatJSON:
+		object, err := e.marshalJSON(record)
+		if err != nil {
+			return fmt.Errorf("can`t marshal record: %w", err)
+		}
+
+		_, err = e.writer.Write(append(object, '\n'))
+		if err != nil {
+			return fmt.Errorf("can`t write json: %w", err)
+		}
+	default:
+		err := e.csv.Write(e.csvRow(record))
+		if err != nil {
+			return fmt.Errorf("can`t write csv row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush записывает буферизованные записи.
+func (e *Exporter) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+
+		if err := e.csv.Error(); err != nil {
+			return fmt.Errorf("can`t flush csv: %w", err)
+		}
+	}
+
+	err := e.writer.Flush()
+	if err != nil {
+		return fmt.Errorf("can`t flush: %w", err)
+	}
+
+	return nil
+}
+
+// marshalJSON возвращает JSON-объект столбцов записи в порядке columns.
+// Числовые поля записываются числами, отсутствующие необязательные значения - null, время - строкой RFC 3339.
+func (e *Exporter) marshalJSON(record *log.Record) ([]byte, error) {
+	object := []byte{'{'}
+
+	for i, column := range e.columns {
+		value, _ := record.Value(column)
+
+		if timeLocal, ok := value.(time.Time); ok {
+			value = timeLocal.Format(timeLayout)
+		}
+
+		name, err := json.Marshal(column)
+		if err != nil {
+			return nil, fmt.Errorf("can`t marshal column name %s: %w", column, err)
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("can`t marshal column %s: %w", column, err)
+		}
+
+		if i != 0 {
+			object = append(object, ',')
+		}
+
+		object = append(append(append(object, name...), ':'), data...)
+	}
+
+	return append(object, '}'), nil
+}
+
+// csvRow возвращает строковые значения столбцов записи в порядке columns. Время записывается в формате RFC 3339.
+func (e *Exporter) csvRow(record *log.Record) []string {
+	row := make([]string, len(e.columns))
+
+	for i, column := range e.columns {
+		if timeLocal, ok := record.TimeField(column); ok {
+			row[i] = timeLocal.Format(timeLayout)
+
+			continue
+		}
+
+		row[i], _ = record.Field(column)
+	}
+
+	return row
+}
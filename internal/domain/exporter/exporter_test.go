@@ -0,0 +1,103 @@
+package exporter_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/exporter"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	requestTime := 0.12
+
+	lines := []string{
+		`70.27.134.194 - - [07/Nov/2024:16:07:55 +0000] "GET /core.svg HTTP/1.1" 200 1373 "-" "Opera/8.62"`,
+		`70.27.134.195 - - [07/Nov/2024:16:07:56 +0000] "POST /api HTTP/1.1" 502 0 "-" "curl, \"8.5\""`,
+	}
+
+	records := []*log.Record{
+		{
+			RemoteAddr:    "70.27.134.194",
+			TimeLocal:     time.Date(2024, time.November, 7, 16, 7, 55, 0, time.UTC),
+			Request:       log.Request{Method: "GET", Resource: "/core.svg", Protocol: "HTTP/1.1"},
+			Status:        200,
+			BodyBytesSent: 1373,
+			HTTPUserAgent: "Opera/8.62",
+			RequestTime:   &requestTime,
+		},
+		{
+			RemoteAddr:           "70.27.134.195",
+			TimeLocal:            time.Date(2024, time.November, 7, 16, 7, 56, 0, time.UTC),
+			Request:              log.Request{Method: "POST", Resource: "/api", Protocol: "HTTP/1.1"},
+			Status:               502,
+			HTTPUserAgent:        `curl, "8.5"`,
+			UpstreamResponseTime: []float64{0.5, 0.25},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		columns []string
+		want    string
+	}{
+		{
+			name:   "raw",
+			format: exporter.FormatRaw,
+			want:   lines[0] + "\n" + lines[1] + "\n",
+		},
+		{
+			name:    "json",
+			format:  exporter.FormatJSON,
+			columns: []string{"time_local", "status", "request_time", "upstream_response_time", "http_user_agent"},
+			want: `{"time_local":"2024-11-07T16:07:55Z","status":200,"request_time":0.12,"upstream_response_time":null,` +
+				`"http_user_agent":"Opera/8.62"}` + "\n" +
+				`{"time_local":"2024-11-07T16:07:56Z","status":502,"request_time":null,"upstream_response_time":[0.5,0.25],` +
+				`"http_user_agent":"curl, \"8.5\""}` + "\n",
+		},
+		{
+			name:    "csv",
+			format:  exporter.FormatCSV,
+			columns: []string{"remote_add", "time_local", "method", "upstream_response_time", "http_user_agent"},
+			want: "remote_add,time_local,method,upstream_response_time,http_user_agent\n" +
+				"70.27.134.194,2024-11-07T16:07:55Z,GET,,Opera/8.62\n" +
+				`70.27.134.195,2024-11-07T16:07:56Z,POST,"0.5, 0.25","curl, ""8.5"""` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+
+			e, err := exporter.New(&got, tt.format, tt.columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, record := range records {
+				err = e.Export(lines[i], record)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = e.Flush()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := exporter.New(&strings.Builder{}, "xml", nil)
+	assert.ErrorAs(t, err, &exporter.ErrUnknownFormat{})
+
+	_, err = exporter.New(&strings.Builder{}, exporter.FormatCSV, []string{"status", "request"})
+	assert.ErrorAs(t, err, &exporter.ErrUnknownColumn{})
+}
@@ -0,0 +1,42 @@
+package filter
+
+import "fmt"
+
+// ErrSyntax - ошибка выражения фильтра, не соответствующего грамматике.
+type ErrSyntax struct {
+	data string
+}
+
+func (e ErrSyntax) Error() string {
+	return fmt.Sprintf("syntax error: %s", e.data)
+}
+
+// ErrUnknownField - ошибка неизвестного поля записи лога в выражении фильтра.
+type ErrUnknownField struct {
+	field string
+}
+
+func (e ErrUnknownField) Error() string {
+	return fmt.Sprintf("%s is not a known field", e.field)
+}
+
+// ErrWrongValue - ошибка значения, с которым нельзя сравнить поле.
+type ErrWrongValue struct {
+	field    string
+	value    string
+	expected string
+}
+
+func (e ErrWrongValue) Error() string {
+	return fmt.Sprintf("%s is not a valid %s to compare with %s", e.value, e.expected, e.field)
+}
+
+// ErrWrongOperator - ошибка оператора, неприменимого к полю.
+type ErrWrongOperator struct {
+	operator string
+	field    string
+}
+
+func (e ErrWrongOperator) Error() string {
+	return fmt.Sprintf("operator %s can not be applied to %s", e.operator, e.field)
+}
@@ -0,0 +1,414 @@
+package filter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// Операторы, не являющиеся операторами сравнения.
+const (
+	operatorMatch    = "=~" // Соответствие регулярному выражению.
+	operatorNotMatch = "!~" // Несоответствие регулярному выражению.
+	operatorIn       = "in" // Принадлежность ip-адреса подсети.
+)
+
+// node описывает интерфейс узла дерева выражения фильтра.
+type node interface {
+	match(record *log.Record) bool // match проверяет, соответствует ли запись узлу.
+}
+
+// Filter - скомпилированное выражение фильтра записей лога.
+//
+// Выражение состоит из сравнений полей записи со значениями, объединённых операторами && и ||,
+// отрицаемых оператором ! и сгруппированных скобками, например
+// `status >= 500 && method == "POST" && !(resource =~ "^/health")`.
+// Значения записываются в двойных кавычках с escape-последовательностями Go или без кавычек, если не содержат
+// пробелов и символов ()!=<>~&|".
+//
+// Операторы ==, !=, <, <=, >, >= сравнивают числовые поля, например status и body_bytes_sent, как числа,
+// поле времени time_local - как время в формате nginx лога или ISO 8601, остальные поля - как строки.
+// Сравнение поля со списком значений, например upstream_response_time, истинно, если истинно хотя бы для одного
+// значения, а сравнение отсутствующего необязательного значения ложно.
+// Операторы =~ и !~ проверяют соответствие строкового представления поля регулярному выражению,
+// оператор in - принадлежность ip-адреса строкового поля, например remote_add, подсети в нотации CIDR.
+type Filter struct {
+	expression string
+	root       node
+}
+
+// New возвращает указатель на Filter, скомпилированный из выражения expression.
+// Ошибки содержат позицию символа выражения, начиная с 1, в которой они обнаружены.
+func New(expression string) (*Filter, error) {
+	root, err := parse(expression)
+	if err != nil {
+		var perr positionError
+
+		errors.As(err, &perr) // Все ошибки разбора содержат позицию.
+
+		return nil, fmt.Errorf("can`t parse filter %q at position %d: %w", expression, perr.position+1, perr.err)
+	}
+
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// Match проверяет, соответствует ли запись лога выражению фильтра.
+func (f *Filter) Match(record *log.Record) bool {
+	return f.root.match(record)
+}
+
+// String возвращает выражение фильтра.
+func (f *Filter) String() string {
+	return f.expression
+}
+
+// parse разбирает выражение фильтра в дерево.
+func parse(expression string) (node, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tk := p.peek(); tk.kind != tokenEnd {
+		return nil, unexpected(tk, "end of filter")
+	}
+
+	return root, nil
+}
+
+// exprParser разбирает лексемы выражения фильтра методом рекурсивного спуска по грамматике:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = field operator value
+type exprParser struct {
+	tokens []token
+	next   int // Индекс следующей лексемы.
+}
+
+// peek возвращает следующую лексему, не считывая её.
+func (p *exprParser) peek() token {
+	return p.tokens[p.next]
+}
+
+// read считывает следующую лексему.
+func (p *exprParser) read() token {
+	tk := p.tokens[p.next]
+
+	if tk.kind != tokenEnd {
+		p.next++
+	}
+
+	return tk
+}
+
+// unexpected возвращает ошибку неожиданной лексемы tk, вместо которой ожидалось expected.
+func unexpected(tk token, expected string) error {
+	if tk.kind == tokenEnd {
+		return positionError{tk.position, ErrSyntax{fmt.Sprintf("unexpected end of filter, expected %s", expected)}}
+	}
+
+	return positionError{tk.position, ErrSyntax{fmt.Sprintf("unexpected %s, expected %s", tk.text, expected)}}
+}
+
+// parseOr разбирает всё выражение или выражение в скобках.
+func (p *exprParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.read()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left, right}
+	}
+
+	if tk := p.peek(); tk.kind != tokenEnd && tk.kind != tokenClose {
+		return nil, unexpected(tk, "&& or ||")
+	}
+
+	return left, nil
+}
+
+// parseAnd разбирает операнды, объединённые оператором &&.
+func (p *exprParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.read()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+// parseUnary разбирает отрицание, выражение в скобках или сравнение.
+func (p *exprParser) parseUnary() (node, error) {
+	switch tk := p.peek(); tk.kind {
+	case tokenNot:
+		p.read()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{operand}, nil
+	case tokenOpen:
+		p.read()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.read(); closing.kind != tokenClose {
+			return nil, unexpected(closing, ")")
+		}
+
+		return inner, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+// parseComparison разбирает сравнение поля со значением.
+func (p *exprParser) parseComparison() (node, error) {
+	fieldToken := p.read()
+	if fieldToken.kind != tokenWord {
+		return nil, unexpected(fieldToken, "field name")
+	}
+
+	field := fieldToken.text
+
+	kind, ok := log.FieldKind(field)
+	if !ok {
+		return nil, positionError{fieldToken.position, ErrUnknownField{field}}
+	}
+
+	operatorToken := p.read()
+	if operatorToken.kind != tokenOperator && (operatorToken.kind != tokenWord || operatorToken.text != operatorIn) {
+		return nil, unexpected(operatorToken, "operator ==, !=, <, <=, >, >=, =~, !~ or in")
+	}
+
+	valueToken := p.read()
+	if valueToken.kind != tokenWord && valueToken.kind != tokenString {
+		return nil, unexpected(valueToken, "value")
+	}
+
+	value, err := unquote(valueToken)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := newComparison(field, kind, operatorToken.text, value)
+	if errors.As(err, &ErrWrongOperator{}) {
+		return nil, positionError{operatorToken.position, err}
+	} else if err != nil {
+		return nil, positionError{valueToken.position, err}
+	}
+
+	return comparison, nil
+}
+
+// newComparison возвращает узел сравнения поля field вида kind со значением value оператором operator.
+func newComparison(field string, kind log.Kind, operator, value string) (node, error) {
+	switch {
+	case operator == operatorMatch || operator == operatorNotMatch:
+		regExp, err := regexp.Compile(value)
+		if err != nil {
+			return nil, ErrWrongValue{field, value, "regular expression"}
+		}
+
+		return regExpNode{field, regExp, operator == operatorNotMatch}, nil
+	case operator == operatorIn && kind == log.KindString:
+		prefix, err := parsePrefix(value)
+		if err != nil {
+			return nil, ErrWrongValue{field, value, "CIDR"}
+		}
+
+		return prefixNode{field, prefix}, nil
+	case operator == operatorIn:
+		return nil, ErrWrongOperator{operator, field}
+	case kind == log.KindNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, ErrWrongValue{field, value, "number"}
+		}
+
+		return numberNode{field, operator, number}, nil
+	case kind == log.KindTime:
+		timeLocal, err := parser.ParseTime(value)
+		if err != nil {
+			return nil, ErrWrongValue{field, value, "time"}
+		}
+
+		return timeNode{field, operator, timeLocal}, nil
+	default:
+		return stringNode{field, operator, value}, nil
+	}
+}
+
+// parsePrefix парсит подсеть в нотации CIDR или отдельный ip-адрес.
+func parsePrefix(value string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("can`t parse prefix: %w", err)
+	}
+
+	return prefix.Masked(), nil
+}
+
+// compare проверяет, удовлетворяет ли результат сравнения result, равный -1, 0 или 1, оператору operator.
+func compare(result int, operator string) bool {
+	switch operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	default:
+		return result >= 0
+	}
+}
+
+// andNode - конъюнкция двух выражений.
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) match(record *log.Record) bool {
+	return n.left.match(record) && n.right.match(record)
+}
+
+// orNode - дизъюнкция двух выражений.
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) match(record *log.Record) bool {
+	return n.left.match(record) || n.right.match(record)
+}
+
+// notNode - отрицание выражения.
+type notNode struct {
+	operand node
+}
+
+func (n notNode) match(record *log.Record) bool {
+	return !n.operand.match(record)
+}
+
+// stringNode - сравнение строкового поля со строкой.
+type stringNode struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (n stringNode) match(record *log.Record) bool {
+	current, _ := record.Field(n.field)
+
+	return compare(cmp.Compare(current, n.value), n.operator)
+}
+
+// numberNode - сравнение числового поля с числом.
+type numberNode struct {
+	field    string
+	operator string
+	value    float64
+}
+
+func (n numberNode) match(record *log.Record) bool {
+	numbers, _ := record.NumberField(n.field)
+
+	for _, number := range numbers {
+		if compare(cmp.Compare(number, n.value), n.operator) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeNode - сравнение поля времени со временем.
+type timeNode struct {
+	field    string
+	operator string
+	value    time.Time
+}
+
+func (n timeNode) match(record *log.Record) bool {
+	current, _ := record.TimeField(n.field)
+
+	return compare(current.Compare(n.value), n.operator)
+}
+
+// regExpNode - проверка соответствия строкового представления поля регулярному выражению.
+type regExpNode struct {
+	field  string
+	regExp *regexp.Regexp
+	negate bool // Указывает, что проверяется несоответствие.
+}
+
+func (n regExpNode) match(record *log.Record) bool {
+	current, _ := record.Field(n.field)
+
+	return n.regExp.MatchString(current) != n.negate
+}
+
+// prefixNode - проверка принадлежности ip-адреса строкового поля подсети.
+type prefixNode struct {
+	field  string
+	prefix netip.Prefix
+}
+
+func (n prefixNode) match(record *log.Record) bool {
+	current, _ := record.Field(n.field)
+
+	addr, err := netip.ParseAddr(current)
+	if err != nil {
+		return false
+	}
+
+	return n.prefix.Contains(addr.Unmap())
+}
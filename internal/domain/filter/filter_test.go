@@ -0,0 +1,148 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/filter"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMatch(t *testing.T) {
+	requestTime := 0.25
+
+	record := &log.Record{
+		RemoteAddr:           "10.1.2.3",
+		TimeLocal:            time.Date(2024, time.November, 17, 16, 7, 52, 0, time.UTC),
+		Request:              log.Request{Method: "POST", Resource: "/api/users", Protocol: "HTTP/1.1"},
+		Status:               503,
+		BodyBytesSent:        1024,
+		HTTPUserAgent:        "curl/8.5.0",
+		RequestTime:          &requestTime,
+		UpstreamResponseTime: []float64{0.1, 0.15},
+		Extra:                map[string]string{"upstream_cache_status": "MISS"},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{name: "numeric comparison", expression: "status >= 500", want: true},
+		{name: "numeric comparison is not lexicographic", expression: "body_bytes_sent > 999", want: true},
+		{name: "quoted string equality", expression: `method == "POST"`, want: true},
+		{name: "bare string inequality", expression: "method != POST", want: false},
+		{name: "regular expression", expression: `resource =~ "^/api/"`, want: true},
+		{name: "negated regular expression", expression: `resource !~ "^/api/"`, want: false},
+		{
+			name:       "conjunction with negation",
+			expression: `status >= 500 && method == "POST" && !(resource =~ "^/health")`,
+			want:       true,
+		},
+		{name: "disjunction", expression: "status == 200 || status == 503", want: true},
+		{name: "and binds tighter than or", expression: "status == 503 || status == 200 && method == GET", want: true},
+		{name: "parentheses", expression: "(status == 503 || status == 200) && method == GET", want: false},
+		{name: "cidr", expression: "remote_add in 10.0.0.0/8", want: true},
+		{name: "cidr mismatch", expression: `remote_add in "192.168.0.0/16"`, want: false},
+		{name: "single address", expression: "remote_add in 10.1.2.3", want: true},
+		{name: "optional number", expression: "request_time > 0.2", want: true},
+		{name: "missing optional number", expression: "request_time > 0.2 && ssl_protocol == TLSv1.3", want: false},
+		{name: "any of multiple values", expression: "upstream_response_time >= 0.15", want: true},
+		{name: "time", expression: `time_local >= "17/Nov/2024:16:00:00 +0000"`, want: true},
+		{name: "iso time", expression: "time_local < 2024-11-17T16:00:00Z", want: false},
+		{name: "extra value", expression: `extra.upstream_cache_status == "MISS"`, want: true},
+		{name: "escaped string", expression: `http_user_agent =~ "^curl/\\d"`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := filter.New(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, f.Match(record))
+			assert.Equal(t, tt.expression, f.String())
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    error
+		wantMsg    string
+	}{
+		{
+			name:       "unknown field",
+			expression: "status >= 500 && verb == POST",
+			wantErr:    filter.ErrUnknownField{},
+			wantMsg:    `at position 18: verb is not a known field`,
+		},
+		{
+			name:       "non-numeric value",
+			expression: "status >= 5xx",
+			wantErr:    filter.ErrWrongValue{},
+			wantMsg:    `at position 11: 5xx is not a valid number to compare with status`,
+		},
+		{
+			name:       "invalid cidr",
+			expression: "remote_add in 10.0.0.0/33",
+			wantErr:    filter.ErrWrongValue{},
+			wantMsg:    `at position 15: 10.0.0.0/33 is not a valid CIDR to compare with remote_add`,
+		},
+		{
+			name:       "cidr on number",
+			expression: "status in 10.0.0.0/8",
+			wantErr:    filter.ErrWrongOperator{},
+			wantMsg:    `at position 8: operator in can not be applied to status`,
+		},
+		{
+			name:       "invalid regular expression",
+			expression: `resource =~ "(["`,
+			wantErr:    filter.ErrWrongValue{},
+			wantMsg:    `at position 13: ([ is not a valid regular expression to compare with resource`,
+		},
+		{
+			name:       "unbalanced parentheses",
+			expression: "(status == 200",
+			wantErr:    filter.ErrSyntax{},
+			wantMsg:    `at position 15: syntax error: unexpected end of filter, expected )`,
+		},
+		{
+			name:       "single ampersand",
+			expression: "status == 200 & method == GET",
+			wantErr:    filter.ErrSyntax{},
+			wantMsg:    `at position 15: syntax error: unexpected &, use &&`,
+		},
+		{
+			name:       "missing operator between comparisons",
+			expression: "status == 200 method == GET",
+			wantErr:    filter.ErrSyntax{},
+			wantMsg:    `at position 15: syntax error: unexpected method, expected && or ||`,
+		},
+		{
+			name:       "unterminated string",
+			expression: `method == "GET`,
+			wantErr:    filter.ErrSyntax{},
+			wantMsg:    `at position 11: syntax error: unterminated string`,
+		},
+		{
+			name:       "empty expression",
+			expression: "",
+			wantErr:    filter.ErrSyntax{},
+			wantMsg:    `at position 1: syntax error: unexpected end of filter, expected field name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := filter.New(tt.expression)
+
+			assert.ErrorAs(t, err, &tt.wantErr)
+			assert.ErrorContains(t, err, tt.wantMsg)
+		})
+	}
+}
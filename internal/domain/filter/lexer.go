@@ -0,0 +1,152 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind - вид лексемы выражения фильтра.
+type tokenKind int
+
+// Виды лексем выражения фильтра.
+const (
+	tokenEnd      tokenKind = iota // Конец выражения.
+	tokenWord                      // Имя поля или значение без кавычек, например 500 или 10.0.0.0/8.
+	tokenString                    // Значение в двойных кавычках.
+	tokenOperator                  // Оператор сравнения.
+	tokenAnd                       // &&.
+	tokenOr                        // ||.
+	tokenNot                       // !.
+	tokenOpen                      // (.
+	tokenClose                     // ).
+)
+
+// operators - операторы сравнения, из которых двухсимвольные указаны раньше односимвольных с тем же началом.
+var operators = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">"}
+
+// specialChars - символы, которые не могут входить в значение без кавычек.
+const specialChars = `()!=<>~&|"`
+
+// token - лексема выражения фильтра, начинающаяся с байта position.
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// positionError - ошибка err, обнаруженная в байте position выражения фильтра.
+type positionError struct {
+	position int
+	err      error
+}
+
+func (e positionError) Error() string {
+	return e.err.Error()
+}
+
+func (e positionError) Unwrap() error {
+	return e.err
+}
+
+// tokenize разбивает expression на лексемы, последней из которых является tokenEnd.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expression); {
+		switch c := expression[i]; {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", position: i})
+			i++
+		case strings.HasPrefix(expression[i:], "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, text: "&&", position: i})
+			i += 2
+		case strings.HasPrefix(expression[i:], "||"):
+			tokens = append(tokens, token{kind: tokenOr, text: "||", position: i})
+			i += 2
+		case c == '"':
+			tk, err := scanString(expression, i)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, tk)
+			i += len(tk.text)
+		case strings.IndexByte(specialChars, c) != -1:
+			tk, err := scanOperator(expression, i)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, tk)
+			i += len(tk.text)
+		default:
+			end := i + strings.IndexFunc(expression[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(specialChars, r)
+			})
+			if end < i {
+				end = len(expression)
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: expression[i:end], position: i})
+			i = end
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, position: len(expression)}), nil
+}
+
+// scanString считывает значение в двойных кавычках, начинающееся с байта start выражения expression.
+// Текст лексемы содержит кавычки, а escape-последовательности раскрываются при разборе значения.
+func scanString(expression string, start int) (token, error) {
+	for i := start + 1; i < len(expression); i++ {
+		switch expression[i] {
+		case '\\':
+			i++
+		case '"':
+			return token{kind: tokenString, text: expression[start : i+1], position: start}, nil
+		}
+	}
+
+	return token{}, positionError{start, ErrSyntax{"unterminated string"}}
+}
+
+// scanOperator считывает оператор, начинающийся с байта start выражения expression.
+func scanOperator(expression string, start int) (token, error) {
+	for _, operator := range operators {
+		if strings.HasPrefix(expression[start:], operator) {
+			return token{kind: tokenOperator, text: operator, position: start}, nil
+		}
+	}
+
+	switch c := expression[start]; c {
+	case '!':
+		return token{kind: tokenNot, text: "!", position: start}, nil
+	case '&', '|':
+		return token{}, positionError{start, ErrSyntax{fmt.Sprintf("unexpected %c, use %c%c", c, c, c)}}
+	case '=':
+		return token{}, positionError{start, ErrSyntax{"unexpected =, use == or =~"}}
+	default:
+		return token{}, positionError{start, ErrSyntax{fmt.Sprintf("unexpected %c", c)}}
+	}
+}
+
+// unquote возвращает значение лексемы tk, раскрывая кавычки и escape-последовательности значения в кавычках.
+func unquote(tk token) (string, error) {
+	if tk.kind != tokenString {
+		return tk.text, nil
+	}
+
+	value, err := strconv.Unquote(tk.text)
+	if err != nil {
+		return "", positionError{tk.position, ErrSyntax{fmt.Sprintf("invalid string %s", tk.text)}}
+	}
+
+	return value, nil
+}
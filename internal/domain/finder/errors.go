@@ -0,0 +1,13 @@
+package finder
+
+import "fmt"
+
+// ErrMixedPaths - ошибка одновременного указания локальных путей и url.
+type ErrMixedPaths struct {
+	local  string
+	remote string
+}
+
+func (e ErrMixedPaths) Error() string {
+	return fmt.Sprintf("local path %s can`t be analyzed together with url %s", e.local, e.remote)
+}
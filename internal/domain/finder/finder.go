@@ -2,93 +2,249 @@ package finder
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
-)
+	"sort"
+	"strings"
 
-const (
-	projectDirectory = "backend_academy_2024_project_3-go-Exzorcist14" // Название директории проекта.
-	relativePath     = "/internal/infrastructure/"                     // Относительный путь от проекта к директории с файлами.
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/loader"
 )
 
+// sniffLength - количество первых байт файла, по которым определяется его содержимое.
+const sniffLength = 512
+
 // Finder умеет находить пути.
-type Finder struct{}
+type Finder struct {
+	BaseDir string // Директория, относительно которой ищутся относительные пути. Если пуста, используется текущая.
+	// Exclude - шаблоны исключаемых файлов. Шаблон без разделителя сравнивается с именем файла,
+	// остальные, как и искомые шаблоны, с путём к файлу относительно BaseDir.
+	Exclude []string
+	// Extensions - расширения выбираемых файлов, например .log. Номер ротации и расширение сжатого файла
+	// в конце имени не учитываются, поэтому access.log.1 и access.log.2.gz имеют расширение .log.
+	// Если Extensions пуст, выбираются файлы с текстовым, в том числе сжатым, содержимым.
+	Extensions []string
+}
+
+// Find возвращает все пути, соответствующие шаблонам patterns, каждый из которых может быть представлен
+// локальным шаблоном или url. Url считается только шаблон со схемой http:// или https://. Локальные шаблоны поддерживают ** для любого количества вложенных директорий.
+// Из найденных директорий и по шаблонам выбираются файлы, соответствующие Extensions, а явно указанные файлы
+// выбираются всегда. Локальные пути возвращаются абсолютными, относительный шаблон ищется в BaseDir.
+// Если пути локальные, то в качестве второго значения возвращает true, иначе - false.
+func (f *Finder) Find(patterns []string) (paths []string, isLocal bool, err error) {
+	urlRegExp := regexp.MustCompile(`(?i)^https?://[^\s]+$`)
+
+	var local, remote []string
+
+	for _, pattern := range patterns {
+		if !urlRegExp.MatchString(pattern) { // Если путь не содержит url.
+			local = append(local, pattern)
+		} else {
+			remote = append(remote, pattern)
+		}
+	}
+
+	if len(local) != 0 && len(remote) != 0 {
+		return nil, false, ErrMixedPaths{local: local[0], remote: remote[0]}
+	}
+
+	if len(remote) != 0 {
+		return remote, false, nil
+	}
 
-// Find возвращает все пути, соответствующие path, который может быть представлен локальным шаблоном или url.
-// Если path локальный, то в качестве второго значения возвращает true, иначе - false.
-func (f *Finder) Find(path string) (paths []string, isLocal bool, err error) {
-	urlRegExp := regexp.MustCompile(`^(https?://)?([a-zA-Z0-9-]+\.)+[a-zA-Z]{2,6}(:\d+)?(/[^\s]*)?$`)
+	seen := make(map[string]bool)
 
-	if !urlRegExp.MatchString(path) { // Если путь не содержит url.
-		paths, err = findByLocalPath(path)
+	for _, pattern := range local {
+		found, err := f.findByLocalPath(pattern)
 		if err != nil {
-			return nil, false, fmt.Errorf("can`t find by local path: %v", err)
+			return nil, false, fmt.Errorf("can`t find by local path %s: %v", pattern, err)
 		}
 
-		isLocal = true
-	} else {
-		paths = []string{path}
-		isLocal = false
+		for _, path := range found {
+			if !seen[path] {
+				seen[path] = true
+
+				paths = append(paths, path)
+			}
+		}
 	}
 
-	return paths, isLocal, nil
+	return paths, true, nil
 }
 
-// findByLocalPath ищет все локальные пути, соответствующие шаблону path.
-func findByLocalPath(path string) ([]string, error) {
-	prefix, err := getAbsolutePrefix(path)
+// findByLocalPath ищет все локальные файлы, соответствующие шаблону path.
+func (f *Finder) findByLocalPath(path string) ([]string, error) {
+	absolutePath, err := f.getAbsolutePath(path)
 	if err != nil {
-		return nil, fmt.Errorf("can`t get absolute prefix: %v", err)
+		return nil, fmt.Errorf("can`t get absolute path: %v", err)
 	}
 
-	postfix := getAbsolutePostfix(path)
-
-	paths, err := filepath.Glob(prefix + path + postfix)
+	matches, err := doublestar.FilepathGlob(absolutePath)
 	if err != nil {
 		return nil, fmt.Errorf("can`t glob path: %v", err)
 	}
 
+	// Путь без шаблонов указывает на файл явно.
+	isExplicit := len(matches) == 1 && filepath.Clean(absolutePath) == matches[0]
+
+	var paths []string
+
+	for _, match := range matches {
+		files, err := f.getFiles(match, isExplicit)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, files...)
+	}
+
+	sort.Strings(paths)
+
 	return paths, nil
 }
 
-// getAbsolutePrefix возвращает абсолютный префикс для пути к файлу.
-// Добавляет к названию файла абсолютный путь до проекта и относительный путь от проекта до директории с файлами.
-func getAbsolutePrefix(path string) (string, error) {
-	prefix := ""
+// getFiles возвращает выбранные файлы, не исключённые шаблонами Exclude: сам path, если он является файлом,
+// или лежащие в директории path файлы. Явно указанный файл выбирается независимо от Extensions.
+func (f *Finder) getFiles(path string, isExplicit bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("can`t get info about %s: %v", path, err)
+	}
+
+	candidates := []string{path}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("can`t read directory %s: %v", path, err)
+		}
+
+		candidates = candidates[:0]
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				candidates = append(candidates, filepath.Join(path, entry.Name()))
+			}
+		}
 
-	if !filepath.IsAbs(path) {
-		absolutePath, err := os.Getwd()
+		isExplicit = false
+	}
+
+	var files []string
+
+	for _, candidate := range candidates {
+		isExcluded, err := f.isExcluded(candidate)
 		if err != nil {
-			return "", fmt.Errorf("can`t get current directory: %v", err)
+			return nil, err
 		}
 
-		for filepath.Base(absolutePath) != projectDirectory {
-			err = os.Chdir("..")
+		if isExcluded {
+			continue
+		}
+
+		if !isExplicit {
+			isSelected, err := f.isSelected(candidate)
 			if err != nil {
-				return "", fmt.Errorf("can`t change directory: %v", err)
+				return nil, err
+			}
+
+			if !isSelected {
+				continue
 			}
+		}
+
+		files = append(files, candidate)
+	}
+
+	return files, nil
+}
+
+// isExcluded проверяет, соответствует ли файл path хотя бы одному шаблону Exclude.
+func (f *Finder) isExcluded(path string) (bool, error) {
+	for _, pattern := range f.Exclude {
+		name := filepath.Base(path)
 
-			absolutePath, err = os.Getwd()
+		if strings.ContainsRune(filepath.ToSlash(pattern), '/') {
+			absolutePattern, err := f.getAbsolutePath(pattern)
 			if err != nil {
-				return "", fmt.Errorf("can`t get current directory: %v", err)
+				return false, fmt.Errorf("can`t get absolute path: %v", err)
 			}
+
+			pattern, name = filepath.ToSlash(absolutePattern), filepath.ToSlash(path)
 		}
 
-		prefix = absolutePath + relativePath
+		ok, err := doublestar.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("can`t match exclude pattern %s: %v", pattern, err)
+		}
+
+		if ok {
+			return true, nil
+		}
 	}
 
-	return prefix, nil
+	return false, nil
 }
 
-// getAbsolutePostfix возвращает абсолютный постфикс для пути к файлу.
-// Если path не является путём к файлу, формирует постфикс-шаблон для всех лежащих внутри txt-файлов.
-func getAbsolutePostfix(path string) (postfix string) {
-	fileRegExp := regexp.MustCompile(`^(.*/)?([^/]+)\.txt$`)
+// isSelected проверяет, соответствует ли файл path расширениям Extensions или, если они не заданы,
+// является ли его содержимое текстом.
+func (f *Finder) isSelected(path string) (bool, error) {
+	if len(f.Extensions) == 0 {
+		return isText(path)
+	}
+
+	ext := getExtension(filepath.Base(path))
+
+	for _, extension := range f.Extensions {
+		if strings.EqualFold(ext, extension) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getAbsolutePath возвращает абсолютный путь к файлу или шаблон абсолютных путей.
+// Относительный путь дополняется абсолютным путём BaseDir или, если она не задана, текущей директории.
+func (f *Finder) getAbsolutePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	base, err := filepath.Abs(f.BaseDir)
+	if err != nil {
+		return "", fmt.Errorf("can`t get absolute base directory: %v", err)
+	}
+
+	return filepath.Join(base, path), nil
+}
+
+// getExtension возвращает расширение имени файла name без номера ротации и расширения сжатого файла,
+// например .log для access.log.1 и access.log.2.gz.
+func getExtension(name string) string {
+	rotationRegExp := regexp.MustCompile(`(\.\d+)?(\.(gz|bz2|zst|xz))?$`)
+
+	return filepath.Ext(rotationRegExp.ReplaceAllString(name, ""))
+}
+
+// isText проверяет по первым байтам, является ли содержимое файла path текстом.
+// Содержимое сжатого файла проверяется после распаковки.
+func isText(path string) (bool, error) {
+	file, err := (&loader.Loader{}).Load(path, true)
+	if err != nil {
+		return false, fmt.Errorf("can`t load %s: %v", path, err)
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
 
-	if !fileRegExp.MatchString(path) {
-		postfix = "/*.txt"
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, fmt.Errorf("can`t read %s: %v", path, err)
 	}
 
-	return postfix
+	return strings.HasPrefix(http.DetectContentType(head[:n]), "text/"), nil
 }
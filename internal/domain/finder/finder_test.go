@@ -1,89 +1,222 @@
 package finder_test
 
 import (
-	"fmt"
-	"regexp"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/finder"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-const (
-	comparisonMessage = "checking for a match number of paths"
-	matchMessage      = "checking for matching paths"
-)
+// gzipped возвращает content, сжатый gzip.
+func gzipped(t *testing.T, content string) string {
+	t.Helper()
 
-func TestFind(t *testing.T) {
-	type args struct {
-		path string
+	var buffer bytes.Buffer
+
+	w := gzip.NewWriter(&buffer)
+
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buffer.String()
+}
+
+// newLogs создаёт во временной директории файлы логов и возвращает путь к ней.
+func newLogs(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"logs/2024-11-07/logs.txt":            "93.180.71.3 - - [17/May/2015:08:05:32 +0000] \"GET /downloads/product_1 HTTP/1.1\"\n",
+		"logs/2024-11-08/logs.txt":            "",
+		"logs/2024-11-08/notes.md":            "# Заметки\n",
+		"hosts/example.com/access.log":        "",
+		"hosts/example.com/access.log.1":      "",
+		"hosts/example.com/access.log.2.gz":   gzipped(t, "93.180.71.3 - - [17/May/2015:08:05:32 +0000]\n"),
+		"hosts/example.com/error.log":         "",
+		"hosts/example.org/access.log":        "",
+		"hosts/example.org/archive/access.db": "\x00\x01\x02\x03",
 	}
 
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func TestFind(t *testing.T) {
+	dir := newLogs(t)
+
 	tests := []struct {
-		name        string
-		args        args
-		wantMatches []string
-		wantIsLocal bool
+		name     string
+		patterns []string
+		finder   finder.Finder // BaseDir заполняется временной директорией.
+		want     []string      // Пути относительно временной директории.
 	}{
 		{
-			name: "the path to the file",
-			args: args{
-				path: `logs/2024-11-07/logs.txt`,
-			},
-			wantMatches: []string{
-				`.*logs[\\/]+2024-11-07[\\/]+logs.txt`,
-			},
-			wantIsLocal: true,
+			name:     "the path to the file",
+			patterns: []string{"logs/2024-11-07/logs.txt"},
+			want:     []string{"logs/2024-11-07/logs.txt"},
 		},
 		{
-			name: "the path to the directory",
-			args: args{
-				path: `logs/2024-11-07`,
-			},
-			wantMatches: []string{
-				`.*logs[\\/]+2024-11-07[\\/]+logs.txt`,
-			},
-			wantIsLocal: true,
+			name:     "the path to the directory",
+			patterns: []string{"logs/2024-11-08"},
+			want:     []string{"logs/2024-11-08/logs.txt", "logs/2024-11-08/notes.md"},
 		},
 		{
-			name: "the path is a local template",
-			args: args{
-				path: `logs/*`,
+			name:     "the path is a local template",
+			patterns: []string{"logs/*"},
+			finder:   finder.Finder{Extensions: []string{".txt"}},
+			want:     []string{"logs/2024-11-07/logs.txt", "logs/2024-11-08/logs.txt"},
+		},
+		{
+			name:     "the absolute path",
+			patterns: []string{filepath.Join(dir, "logs", "2024-11-07")},
+			want:     []string{"logs/2024-11-07/logs.txt"},
+		},
+		{
+			name:     "recursive template",
+			patterns: []string{"hosts/**"},
+			want: []string{
+				"hosts/example.com/access.log",
+				"hosts/example.com/access.log.1",
+				"hosts/example.com/access.log.2.gz",
+				"hosts/example.com/error.log",
+				"hosts/example.org/access.log",
 			},
-			wantMatches: []string{
-				`.*logs[\\/]+2024-11-07[\\/]+logs.txt`,
-				`.*logs[\\/]+2024-11-08[\\/]+logs.txt`,
+		},
+		{
+			name:     "recursive template of file names",
+			patterns: []string{"**/access.log*"},
+			want: []string{
+				"hosts/example.com/access.log",
+				"hosts/example.com/access.log.1",
+				"hosts/example.com/access.log.2.gz",
+				"hosts/example.org/access.log",
 			},
-			wantIsLocal: true,
 		},
 		{
-			name: "the path is the url",
-			args: args{
-				path: `https://raw.githubusercontent.com/elastic/examples/master/Common%20Data%20Formats/nginx_logs/nginx_logs`,
+			name:     "several paths",
+			patterns: []string{"logs/2024-11-07", "hosts/example.org", "logs/*/logs.txt"},
+			want:     []string{"logs/2024-11-07/logs.txt", "hosts/example.org/access.log", "logs/2024-11-08/logs.txt"},
+		},
+		{
+			name:     "exclude patterns",
+			patterns: []string{"hosts/**", "logs/*"},
+			finder:   finder.Finder{Exclude: []string{"error.*", "hosts/example.org/**", "*.md", "*.gz"}},
+			want: []string{
+				"hosts/example.com/access.log",
+				"hosts/example.com/access.log.1",
+				"logs/2024-11-07/logs.txt",
+				"logs/2024-11-08/logs.txt",
 			},
-			wantMatches: []string{
-				`https://raw.githubusercontent.com/elastic/examples/master/Common%20Data%20Formats/nginx_logs/nginx_logs`,
+		},
+		{
+			name:     "extensions with rotation numbers",
+			patterns: []string{"**"},
+			finder:   finder.Finder{Extensions: []string{".log"}},
+			want: []string{
+				"hosts/example.com/access.log",
+				"hosts/example.com/access.log.1",
+				"hosts/example.com/access.log.2.gz",
+				"hosts/example.com/error.log",
+				"hosts/example.org/access.log",
 			},
-			wantIsLocal: false,
+		},
+		{
+			name:     "the explicit file is selected regardless of extensions",
+			patterns: []string{"hosts/example.org/archive/access.db"},
+			finder:   finder.Finder{Extensions: []string{".log"}},
+			want:     []string{"hosts/example.org/archive/access.db"},
+		},
+		{
+			name:     "the path does not exist",
+			patterns: []string{"reports/*"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPaths, gotIsLocal, err := (&finder.Finder{}).Find(tt.args.path)
+			f := tt.finder
+			f.BaseDir = dir
 
-			assert.True(t, len(gotPaths) == len(tt.wantMatches), comparisonMessage)
+			gotPaths, gotIsLocal, err := f.Find(tt.patterns)
+			require.NoError(t, err)
 
-			fmt.Println("Some debug information:", tt.name, gotPaths)
-
-			for i, gotPath := range gotPaths {
-				re := regexp.MustCompile(tt.wantMatches[i])
-
-				assert.True(t, re.MatchString(gotPath), matchMessage)
+			var want []string
+			for _, path := range tt.want {
+				want = append(want, filepath.Join(dir, filepath.FromSlash(path)))
 			}
 
-			assert.Equal(t, tt.wantIsLocal, gotIsLocal)
-			assert.NoError(t, err)
+			assert.Equal(t, want, gotPaths)
+			assert.True(t, gotIsLocal)
 		})
 	}
 }
+
+func TestFindRelativeToWorkingDirectory(t *testing.T) {
+	dir := newLogs(t)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Временная директория задаётся относительно текущей, которая не изменяется при поиске.
+	rel, err := filepath.Rel(wd, filepath.Join(dir, "logs"))
+	require.NoError(t, err)
+
+	gotPaths, gotIsLocal, err := (&finder.Finder{}).Find([]string{filepath.ToSlash(rel) + "/*/logs.txt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "logs", "2024-11-07", "logs.txt"),
+		filepath.Join(dir, "logs", "2024-11-08", "logs.txt"),
+	}, gotPaths)
+	assert.True(t, gotIsLocal)
+
+	after, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, wd, after)
+}
+
+func TestFindFileNames(t *testing.T) {
+	dir := newLogs(t)
+
+	// Имена файлов, похожие на доменные имена, ищутся локально.
+	gotPaths, gotIsLocal, err := (&finder.Finder{BaseDir: filepath.Join(dir, "hosts", "example.com")}).Find(
+		[]string{"access.log", "access.log.2.gz"},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "hosts", "example.com", "access.log"),
+		filepath.Join(dir, "hosts", "example.com", "access.log.2.gz"),
+	}, gotPaths)
+	assert.True(t, gotIsLocal)
+}
+
+func TestFindURL(t *testing.T) {
+	urls := []string{
+		`https://raw.githubusercontent.com/elastic/examples/master/Common%20Data%20Formats/nginx_logs/nginx_logs`,
+		`https://example.com/access.log`,
+	}
+
+	gotPaths, gotIsLocal, err := (&finder.Finder{BaseDir: t.TempDir()}).Find(urls)
+	require.NoError(t, err)
+
+	assert.Equal(t, urls, gotPaths)
+	assert.False(t, gotIsLocal)
+
+	_, _, err = (&finder.Finder{BaseDir: t.TempDir()}).Find([]string{"logs/*", urls[1]})
+	assert.ErrorAs(t, err, &finder.ErrMixedPaths{})
+}
@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// magicLength - количество первых байт, по которым определяется формат сжатия.
+const magicLength = 10
+
+// Сигнатуры форматов сжатия.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	// Сигнатура bzip2 BZh, за которой следуют размер блока от 1 до 9 и сигнатура первого блока или конца потока.
+	bzip2Magic       = []byte("BZh")
+	bzip2BlockMagic  = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2StreamMagic = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// decompressor - распаковывающий reader, закрывающий вместе с собой источник сжатых данных.
+type decompressor struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressor) Close() error {
+	var errs []error
+
+	for _, closer := range d.closers {
+		errs = append(errs, closer.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// decompress возвращает source, если его данные не сжаты, или распаковывающий их reader,
+// если первые байты данных соответствуют сигнатуре gzip, bzip2, zstd или xz.
+func decompress(source io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(source)
+
+	// Данные короче сигнатур считаются несжатыми, поэтому ошибка io.EOF не учитывается.
+	magic, err := buffered.Peek(magicLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("can`t read magic bytes: %w", err)
+	}
+
+	var decompressed io.Reader
+
+	closers := []io.Closer{source}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		decompressed, err = gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("can`t read gzip header: %w", err)
+		}
+	case isBzip2(magic):
+		decompressed = bzip2.NewReader(buffered)
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(buffered, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("can`t create zstd decoder: %w", err)
+		}
+
+		// Декодер освобождает свои ресурсы только при закрытии.
+		reader := decoder.IOReadCloser()
+		decompressed, closers = reader, []io.Closer{reader, source}
+	case bytes.HasPrefix(magic, xzMagic):
+		decompressed, err = xz.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("can`t read xz header: %w", err)
+		}
+	default:
+		decompressed = buffered
+	}
+
+	return &decompressor{Reader: decompressed, closers: closers}, nil
+}
+
+// isBzip2 проверяет, соответствуют ли первые байты magic сигнатуре bzip2.
+func isBzip2(magic []byte) bool {
+	if len(magic) < magicLength || !bytes.HasPrefix(magic, bzip2Magic) || magic[3] < '1' || magic[3] > '9' {
+		return false
+	}
+
+	return bytes.Equal(magic[4:], bzip2BlockMagic) || bytes.Equal(magic[4:], bzip2StreamMagic)
+}
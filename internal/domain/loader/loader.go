@@ -12,6 +12,7 @@ import (
 type Loader struct{}
 
 // Load загружает данные для чтения.
+// Данные, сжатые gzip, bzip2, zstd или xz, распаковываются. Формат сжатия определяется по первым байтам, а не по расширению.
 func (l *Loader) Load(path string, isLocal bool) (source io.ReadCloser, err error) {
 	if isLocal {
 		source, err = loadLocal(path)
@@ -25,7 +26,14 @@ func (l *Loader) Load(path string, isLocal bool) (source io.ReadCloser, err erro
 		}
 	}
 
-	return source, nil
+	decompressed, err := decompress(source)
+	if err != nil {
+		source.Close()
+
+		return nil, fmt.Errorf("can`t decompress %s: %v", path, err)
+	}
+
+	return decompressed, nil
 }
 
 // loadLocal загружает локальные данные.
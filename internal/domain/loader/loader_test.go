@@ -0,0 +1,140 @@
+package loader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/loader"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+const line = `93.180.71.3 - - [17/May/2015:08:05:32 +0000] "GET /downloads/product_1 HTTP/1.1" 304 0 "-" ` +
+	`"Debian APT-HTTP/1.3 (0.8.16~exp12ubuntu10.21)"` + "\n"
+
+// bzip2Line - line, сжатая bzip2. Стандартная библиотека не умеет сжимать bzip2, поэтому данные сжаты заранее.
+const bzip2Line = "\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xeb\x5c\xd5\x3d\x00\x00\x2a\x5f\x80\x40\x10\x50\x6b\xff" +
+	"\xf0\x26\xc2\x44\x0a\xbe\x25\xde\xe1\x20\x00\x72\x2a\x00\x06\x80\x00\x68\x00\xc8\xd0\xf2\x83\x55" +
+	"\x3f\xd4\x13\x46\x8c\xa6\xd4\x0f\x53\x46\x6a\x1e\xa0\x06\x80\x6a\x89\x93\xde\x6b\xc2\xda\x3a\xc1" +
+	"\x6f\x28\x70\x04\xe4\x5b\x13\xa6\xab\x12\x08\x90\xfb\x1a\xb1\x5a\x28\xa7\x3a\x86\x88\x57\xb0\x4e" +
+	"\x2a\xd6\x1d\xef\x16\xd1\xc8\xb5\x88\x79\x12\xb5\xd4\xac\x90\xb0\x51\x7c\x30\x0c\x8c\xca\x2e\xb2" +
+	"\xe1\xbe\x7b\x52\x35\x23\x79\xfb\xe2\x79\x4d\xc4\x2a\x1a\x40\xf6\x76\x41\xcf\x80\x06\x0d\xd7\x17" +
+	"\xa5\x3b\x05\x8e\x48\x87\xf1\x77\x24\x53\x85\x09\x0e\xb5\xcd\x53\xd0"
+
+// compress возвращает line, сжатую writer.
+func compress(t *testing.T, newWriter func(w io.Writer) (io.WriteCloser, error)) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	w, err := newWriter(&buffer)
+	require.NoError(t, err)
+
+	_, err = io.WriteString(w, line)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buffer.Bytes()
+}
+
+// newFixtures возвращает line в несжатом виде и сжатую каждым из поддерживаемых форматов.
+func newFixtures(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	return map[string][]byte{
+		"plain": []byte(line),
+		"empty": nil,
+		"gzip": compress(t, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		}),
+		"bzip2": []byte(bzip2Line),
+		"zstd": compress(t, func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		}),
+		"xz": compress(t, func(w io.Writer) (io.WriteCloser, error) {
+			return xz.NewWriter(w)
+		}),
+	}
+}
+
+func TestLoadLocal(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range newFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			// Расширение не соответствует формату сжатия, который определяется только по первым байтам.
+			path := filepath.Join(dir, name+".txt")
+			require.NoError(t, os.WriteFile(path, content, 0o600))
+
+			source, err := (&loader.Loader{}).Load(path, true)
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(source)
+			require.NoError(t, err)
+			require.NoError(t, source.Close())
+
+			if name == "empty" {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, line, string(got))
+			}
+		})
+	}
+}
+
+func TestLoadRemote(t *testing.T) {
+	fixtures := newFixtures(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := fixtures[filepath.Base(r.URL.Path)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	for _, name := range []string{"plain", "gzip", "bzip2", "zstd", "xz"} {
+		t.Run(name, func(t *testing.T) {
+			source, err := (&loader.Loader{}).Load(server.URL+"/logs/"+name, false)
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(source)
+			require.NoError(t, err)
+			require.NoError(t, source.Close())
+
+			assert.Equal(t, line, string(got))
+		})
+	}
+
+	_, err := (&loader.Loader{}).Load(server.URL+"/logs/missing", false)
+	assert.ErrorContains(t, err, "response code 404")
+}
+
+func TestLoadCorrupted(t *testing.T) {
+	gzipped := compress(t, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+
+	path := filepath.Join(t.TempDir(), "access.log.gz")
+	require.NoError(t, os.WriteFile(path, gzipped[:len(gzipped)/2], 0o600))
+
+	source, err := (&loader.Loader{}).Load(path, true)
+	require.NoError(t, err)
+
+	defer source.Close()
+
+	_, err = io.ReadAll(source)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
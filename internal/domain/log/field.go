@@ -0,0 +1,197 @@
+package log
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fieldTag = "field" // Тег, задающий имя поля Record.
+	// ExtraPrefix - префикс имени поля, ссылающегося на значение Record.Extra, например extra.edge_location.
+	ExtraPrefix    = "extra."
+	valueSeparator = ", " // Разделитель значений многозначного поля, как в переменных nginx.
+)
+
+// fieldIndexes сопоставляет именам полей Record индексы их struct-полей, в том числе вложенных.
+// fieldNames содержит имена полей Record в порядке их объявления.
+var fieldIndexes, fieldNames = indexFields(reflect.TypeOf(Record{}), nil)
+
+// indexFields собирает имена полей структуры typ, заданные тегом field, и индексы их struct-полей.
+// Вложенные структуры без тега field, кроме time.Time, обходятся рекурсивно.
+func indexFields(typ reflect.Type, prefix []int) (indexes map[string][]int, names []string) {
+	indexes = make(map[string][]int)
+
+	for i := range typ.NumField() {
+		structField := typ.Field(i)
+		index := append(append([]int{}, prefix...), i)
+
+		if name, ok := structField.Tag.Lookup(fieldTag); ok {
+			indexes[name] = index
+			names = append(names, name)
+
+			continue
+		}
+
+		if structField.Type.Kind() == reflect.Struct && structField.Type != reflect.TypeOf(time.Time{}) {
+			nestedIndexes, nestedNames := indexFields(structField.Type, index)
+
+			for _, name := range nestedNames {
+				indexes[name] = nestedIndexes[name]
+			}
+
+			names = append(names, nestedNames...)
+		}
+	}
+
+	return indexes, names
+}
+
+// FieldNames возвращает имена полей Record, по которым записи могут фильтроваться.
+func FieldNames() []string {
+	return append([]string{}, fieldNames...)
+}
+
+// IsField проверяет, является ли name именем поля Record или значения Record.Extra.
+func IsField(name string) bool {
+	if _, ok := fieldIndexes[name]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(name, ExtraPrefix) && len(name) > len(ExtraPrefix)
+}
+
+// Field возвращает строковое представление значения поля name записи.
+// Отсутствующие необязательные значения представляются пустой строкой.
+// Если name не является именем поля, возвращает false.
+func (r *Record) Field(name string) (string, bool) {
+	if key, ok := strings.CutPrefix(name, ExtraPrefix); ok && key != "" {
+		return r.Extra[key], true
+	}
+
+	index, ok := fieldIndexes[name]
+	if !ok {
+		return "", false
+	}
+
+	return formatValue(reflect.ValueOf(r).Elem().FieldByIndex(index)), true
+}
+
+// Value возвращает значение поля name записи: string, int, time.Time, *float64 или []float64.
+// Значения Record.Extra возвращаются строками. Если name не является именем поля, возвращает false.
+func (r *Record) Value(name string) (any, bool) {
+	if key, ok := strings.CutPrefix(name, ExtraPrefix); ok && key != "" {
+		return r.Extra[key], true
+	}
+
+	index, ok := fieldIndexes[name]
+	if !ok {
+		return nil, false
+	}
+
+	return reflect.ValueOf(r).Elem().FieldByIndex(index).Interface(), true
+}
+
+// formatValue возвращает строковое представление значения поля Record.
+func formatValue(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.String()
+	case *float64:
+		if v == nil {
+			return ""
+		}
+
+		return formatSeconds(*v)
+	case []float64:
+		values := make([]string, len(v))
+
+		for i, seconds := range v {
+			values[i] = formatSeconds(seconds)
+		}
+
+		return strings.Join(values, valueSeparator)
+	default:
+		return ""
+	}
+}
+
+// formatSeconds возвращает строковое представление длительности в секундах.
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
+
+// Kind - вид значения поля Record, определяющий способ сравнения его значений.
+type Kind int
+
+// Виды значений полей Record.
+const (
+	KindString Kind = iota // Строка.
+	KindNumber             // Число, необязательное число или список чисел.
+	KindTime               // Время.
+)
+
+// FieldKind возвращает вид значения поля name. Значения Record.Extra являются строками.
+// Если name не является именем поля, возвращает false.
+func FieldKind(name string) (Kind, bool) {
+	if !IsField(name) {
+		return KindString, false
+	}
+
+	index, ok := fieldIndexes[name]
+	if !ok {
+		return KindString, true
+	}
+
+	switch reflect.TypeOf(Record{}).FieldByIndex(index).Type {
+	case reflect.TypeOf(0), reflect.TypeOf((*float64)(nil)), reflect.TypeOf([]float64{}):
+		return KindNumber, true
+	case reflect.TypeOf(time.Time{}):
+		return KindTime, true
+	default:
+		return KindString, true
+	}
+}
+
+// NumberField возвращает значения числового поля name записи: одно значение целого поля,
+// ни одного или одно значение необязательного поля и все значения списка.
+// Если name не является именем числового поля, возвращает false.
+func (r *Record) NumberField(name string) ([]float64, bool) {
+	index, ok := fieldIndexes[name]
+	if !ok {
+		return nil, false
+	}
+
+	switch v := reflect.ValueOf(r).Elem().FieldByIndex(index).Interface().(type) {
+	case int:
+		return []float64{float64(v)}, true
+	case *float64:
+		if v == nil {
+			return nil, true
+		}
+
+		return []float64{*v}, true
+	case []float64:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// TimeField возвращает значение поля времени name записи.
+// Если name не является именем поля времени, возвращает false.
+func (r *Record) TimeField(name string) (time.Time, bool) {
+	index, ok := fieldIndexes[name]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	timeLocal, ok := reflect.ValueOf(r).Elem().FieldByIndex(index).Interface().(time.Time)
+
+	return timeLocal, ok
+}
@@ -0,0 +1,149 @@
+package log_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordField(t *testing.T) {
+	requestTime := 0.12
+
+	record := &log.Record{
+		RemoteAddr:           "244.103.237.229",
+		TimeLocal:            time.Date(2024, time.November, 17, 16, 7, 52, 0, time.UTC),
+		Request:              log.Request{Method: "GET", Resource: "/reciprocal.hmtl", Protocol: "HTTP/1.1"},
+		Status:               200,
+		RequestTime:          &requestTime,
+		UpstreamResponseTime: []float64{0.118, 0.002},
+		Host:                 "example.com",
+		Extra:                map[string]string{"upstream_cache_status": "MISS"},
+	}
+
+	tests := []struct {
+		name   string
+		field  string
+		want   string
+		wantOk bool
+	}{
+		{name: "string field", field: "remote_add", want: "244.103.237.229", wantOk: true},
+		{name: "time field", field: "time_local", want: "2024-11-17 16:07:52 +0000 UTC", wantOk: true},
+		{name: "request field", field: "resource", want: "/reciprocal.hmtl", wantOk: true},
+		{name: "int field", field: "status", want: "200", wantOk: true},
+		{name: "optional field", field: "request_time", want: "0.12", wantOk: true},
+		{name: "multi-value field", field: "upstream_response_time", want: "0.118, 0.002", wantOk: true},
+		{name: "empty field", field: "ssl_protocol", want: "", wantOk: true},
+		{name: "extra field", field: "extra.upstream_cache_status", want: "MISS", wantOk: true},
+		{name: "unknown field", field: "request", want: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := record.Field(tt.field)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantOk, log.IsField(tt.field))
+		})
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	want := []string{
+		"remote_add", "remote_user", "time_local", "method", "resource", "protocol", "status", "body_bytes_sent",
+		"http_referer", "http_user_agent", "request_time", "upstream_response_time", "upstream_addr", "host",
+		"request_id", "ssl_protocol",
+	}
+
+	assert.Equal(t, want, log.FieldNames())
+}
+
+func TestFieldKind(t *testing.T) {
+	tests := []struct {
+		field  string
+		want   log.Kind
+		wantOk bool
+	}{
+		{field: "remote_add", want: log.KindString, wantOk: true},
+		{field: "time_local", want: log.KindTime, wantOk: true},
+		{field: "status", want: log.KindNumber, wantOk: true},
+		{field: "request_time", want: log.KindNumber, wantOk: true},
+		{field: "upstream_response_time", want: log.KindNumber, wantOk: true},
+		{field: "extra.upstream_cache_status", want: log.KindString, wantOk: true},
+		{field: "request", want: log.KindString, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, ok := log.FieldKind(tt.field)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestRecordTypedFields(t *testing.T) {
+	requestTime := 0.12
+	timeLocal := time.Date(2024, time.November, 17, 16, 7, 52, 0, time.UTC)
+
+	record := &log.Record{
+		TimeLocal:            timeLocal,
+		Status:               200,
+		RequestTime:          &requestTime,
+		UpstreamResponseTime: []float64{0.118, 0.002},
+	}
+
+	numbers, ok := record.NumberField("status")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{200}, numbers)
+
+	numbers, ok = record.NumberField("request_time")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{0.12}, numbers)
+
+	numbers, ok = record.NumberField("upstream_response_time")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{0.118, 0.002}, numbers)
+
+	_, ok = record.NumberField("resource")
+	assert.False(t, ok)
+
+	got, ok := record.TimeField("time_local")
+	assert.True(t, ok)
+	assert.Equal(t, timeLocal, got)
+
+	_, ok = record.TimeField("status")
+	assert.False(t, ok)
+}
+
+func TestRecordValue(t *testing.T) {
+	record := &log.Record{
+		Request: log.Request{Method: "GET"},
+		Status:  200,
+		Extra:   map[string]string{"upstream_cache_status": "MISS"},
+	}
+
+	tests := []struct {
+		field  string
+		want   any
+		wantOk bool
+	}{
+		{field: "method", want: "GET", wantOk: true},
+		{field: "status", want: 200, wantOk: true},
+		{field: "request_time", want: (*float64)(nil), wantOk: true},
+		{field: "extra.upstream_cache_status", want: "MISS", wantOk: true},
+		{field: "request", want: nil, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, ok := record.Value(tt.field)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
@@ -6,19 +6,28 @@ import (
 
 // Request - представление http-запроса.
 type Request struct {
-	Method   string
-	Resource string
-	Protocol string
+	Method   string `field:"method"`
+	Resource string `field:"resource"`
+	Protocol string `field:"protocol"`
 }
 
 // Record - промежуточное представление строки nginx лога.
+// Тег field задаёт имя поля, по которому записи фильтруются.
 type Record struct {
-	RemoteAddr    string
-	RemoteUser    string
-	TimeLocal     time.Time
+	RemoteAddr    string    `field:"remote_add"`
+	RemoteUser    string    `field:"remote_user"`
+	TimeLocal     time.Time `field:"time_local"`
 	Request       Request
-	Status        int
-	BodyBytesSent int
-	HTTPRefer     string
-	HTTPUserAgent string
+	Status        int    `field:"status"`
+	BodyBytesSent int    `field:"body_bytes_sent"`
+	HTTPRefer     string `field:"http_referer"`
+	HTTPUserAgent string `field:"http_user_agent"`
+
+	RequestTime          *float64          `field:"request_time"`           // Время обработки запроса в секундах, если известно.
+	UpstreamResponseTime []float64         `field:"upstream_response_time"` // Времена ответа upstream-серверов в секундах по попыткам.
+	UpstreamAddr         string            `field:"upstream_addr"`          // Адреса upstream-серверов в записи nginx.
+	Host                 string            `field:"host"`                   // Виртуальный хост, обработавший запрос.
+	RequestID            string            `field:"request_id"`             // Уникальный идентификатор запроса.
+	SSLProtocol          string            `field:"ssl_protocol"`           // Протокол установленного SSL-соединения.
+	Extra                map[string]string // Переменные формата лога, не имеющие собственного поля.
 }
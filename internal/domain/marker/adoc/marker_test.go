@@ -2,6 +2,7 @@ package adoc_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/adoc"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
@@ -29,7 +30,6 @@ func TestMarkUp(t *testing.T) {
 					"2024-11-08 14:39:44 +0000 +0000",
 					"2024-11-08 14:40:03 +0000 +0000",
 					"-",
-					"-",
 					10,
 					map[string]int{
 						"/6th%20generation.php":                                            1,
@@ -88,7 +88,6 @@ func TestMarkUp(t *testing.T) {
 				"|Начальная дата|2024-11-08 14:39:44 +0000 +0000\n" +
 				"|Конечная дата|2024-11-08 14:40:03 +0000 +0000\n" +
 				"|Фильтр|-\n" +
-				"|Значение фильтра|-\n" +
 				"|Количество запросов|10\n" +
 				"|Средний размер ответа|1474.9\n" +
 				"|95p размера ответа|2733.5\n" +
@@ -151,6 +150,163 @@ func TestMarkUp(t *testing.T) {
 				"|Opera/10.78 (Macintosh; U; Intel Mac OS X 10_8_8; en-US) Presto/2.12.254 Version/12.00|1\n" +
 				"|===\n",
 		},
+		{
+			name: "checking the latency section of a marked-up report",
+			args: args{
+				rep: report.Report{
+					Files:                 []string{"logs.txt"},
+					From:                  "-",
+					To:                    "-",
+					Filter:                "-",
+					RequestsCount:         3,
+					MostFrequentResources: []report.DataWithCount[string]{{Data: "/core.svg", Count: 2}, {Data: "/health", Count: 1}},
+					MostFrequentCodes:     []report.DataWithCount[int]{{Data: 200, Count: 3}},
+					MostFrequentClients:   []report.DataWithCount[string]{{Data: "70.27.134.194", Count: 3}},
+					MostFrequentAgents:    []report.DataWithCount[string]{{Data: "Opera/8.62", Count: 3}},
+					AverageResponseSize:   1373,
+					Latency: report.Latency{
+						Count: 3, Min: 0.1, Average: 0.2, Max: 0.3, Percentiles: []float64{0.2, 0.28, 0.29, 0.298, 0.2998},
+					},
+					ResourceLatencies: []report.ResourceLatency{
+						{Resource: "/core.svg", Latency: report.Latency{
+							Count: 2, Min: 0.1, Average: 0.2, Max: 0.3, Percentiles: []float64{0.1, 0.3, 0.3, 0.3, 0.3},
+						}},
+						{Resource: "/health", Latency: report.Latency{
+							Count: 1, Min: 0.1, Average: 0.1, Max: 0.1, Percentiles: []float64{0.1, 0.1, 0.1, 0.1, 0.1},
+						}},
+					},
+				},
+				highest: 1,
+			},
+			want: "== Общая информация\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Метрика|Значение\n" +
+				"\n" +
+				"|Файл(-ы)|logs.txt +\n" +
+				"\n" +
+				"|Начальная дата|-\n" +
+				"|Конечная дата|-\n" +
+				"|Фильтр|-\n" +
+				"|Количество запросов|3\n" +
+				"|Средний размер ответа|1373\n" +
+				"|95p размера ответа|0\n" +
+				"|===\n" +
+				"== Время обработки запросов\n" +
+				"[cols=\"^,^,^,^,^,^,^,^,^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Ресурс|Количество|Мин., с|Среднее, с|Макс., с|p50|p90|p95|p99|p99.9\n" +
+				"\n" +
+				"|Все ресурсы|3|0.100|0.200|0.300|0.200|0.280|0.290|0.298|0.300\n" +
+				"|/core.svg|2|0.100|0.200|0.300|0.100|0.300|0.300|0.300|0.300\n" +
+				"|===\n" +
+				"== Запрашиваемые ресурсы\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Ресурс|Количество\n" +
+				"\n" +
+				"|/core.svg|2\n" +
+				"|===\n" +
+				"== Коды ответа\n" +
+				"[cols=\"^,^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Код|Имя|Количество\n" +
+				"\n" +
+				"|200|OK|3\n" +
+				"|===\n" +
+				"== IP-адреса клиентов\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Клиент|Количество\n" +
+				"\n" +
+				"|70.27.134.194|3\n" +
+				"|===\n" +
+				"== HTTP-заголовки User-Agent\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Агент|Количество\n" +
+				"\n" +
+				"|Opera/8.62|3\n" +
+				"|===\n",
+		},
+		{
+			name: "checking the buckets section of a marked-up report",
+			args: args{
+				rep: report.Report{
+					Files:               []string{"logs.txt"},
+					From:                "-",
+					To:                  "-",
+					Filter:              "-",
+					RequestsCount:       3,
+					AverageResponseSize: 100,
+					BucketSize:          time.Minute,
+					Buckets: []report.Bucket{
+						{
+							Start:         time.Date(2024, time.November, 7, 16, 7, 0, 0, time.UTC),
+							RequestsCount: 2,
+							StatusClasses: []int{0, 1, 0, 0, 1},
+							BytesSent:     250,
+							Latency: report.Latency{
+								Count: 1, Min: 0.1, Average: 0.1, Max: 0.1, Percentiles: []float64{0.1, 0.1, 0.1, 0.1, 0.1},
+							},
+						},
+						{
+							Start:         time.Date(2024, time.November, 7, 16, 9, 0, 0, time.UTC),
+							RequestsCount: 1,
+							StatusClasses: []int{0, 1, 0, 0, 0},
+							BytesSent:     50,
+						},
+					},
+				},
+				highest: 1,
+			},
+			want: "== Общая информация\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Метрика|Значение\n" +
+				"\n" +
+				"|Файл(-ы)|logs.txt +\n" +
+				"\n" +
+				"|Начальная дата|-\n" +
+				"|Конечная дата|-\n" +
+				"|Фильтр|-\n" +
+				"|Количество запросов|3\n" +
+				"|Средний размер ответа|100\n" +
+				"|95p размера ответа|0\n" +
+				"|===\n" +
+				"== Запросы по времени\n" +
+				"[cols=\"^,^,^,^,^,^,^,^,^,^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Начало|Запросы|1xx|2xx|3xx|4xx|5xx|Байты|Среднее, с|p95, с|Макс., с\n" +
+				"\n" +
+				"|2024-11-07 16:07 Z|2|0|1|0|0|1|250|0.100|0.100|0.100\n" +
+				"|2024-11-07 16:09 Z|1|0|1|0|0|0|50|-|-|-\n" +
+				"|===\n" +
+				"== Запрашиваемые ресурсы\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Ресурс|Количество\n" +
+				"\n" +
+				"|===\n" +
+				"== Коды ответа\n" +
+				"[cols=\"^,^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Код|Имя|Количество\n" +
+				"\n" +
+				"|===\n" +
+				"== IP-адреса клиентов\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Клиент|Количество\n" +
+				"\n" +
+				"|===\n" +
+				"== HTTP-заголовки User-Agent\n" +
+				"[cols=\"^,^\", options=\"header\"]\n" +
+				"|===\n" +
+				"|Агент|Количество\n" +
+				"\n" +
+				"|===\n",
+		},
 	}
 
 	for _, tt := range tests {
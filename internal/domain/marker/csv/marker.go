@@ -0,0 +1,224 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// Имена таблиц отчёта, из которых FileName составляет имена их файлов.
+const (
+	TableGeneralInfo = "general"
+	TableFormats     = "formats"
+	TableMalformed   = "malformed"
+	TableLatency     = "latency"
+	TableBuckets     = "buckets"
+	TableResources   = "resources"
+	TableCodes       = "codes"
+	TableClients     = "clients"
+	TableAgents      = "agents"
+)
+
+// Marker умеет размечать таблицы отчёта в формате CSV (RFC 4180), по файлу на каждую таблицу отчёта.
+// Если Comma равен '\t', таблицы размечаются в формате TSV с расширением .tsv.
+type Marker struct {
+	Comma rune // Разделитель значений. Если равен 0, используется запятая.
+}
+
+// table - таблица отчёта с именем name.
+type table struct {
+	name    string
+	records [][]string
+}
+
+// MarkUpTables размечает таблицы отчёта, возвращая содержимое их файлов по именам, записывая первые highest
+// значений таблиц, не содержащих общую информацию. Как и в других форматах, таблицы необязательных разделов
+// размечаются, только если они не пусты.
+func (p *Marker) MarkUpTables(rep *report.Report, highest int) (map[string][]byte, error) {
+	tables := []table{
+		generalInfo(rep),
+		formats(rep),
+		malformed(rep),
+		latency(rep, highest),
+		buckets(rep),
+		resources(rep, highest),
+		codes(rep, highest),
+		clients(rep, highest),
+		agents(rep, highest),
+	}
+
+	comma := ','
+
+	if p.Comma != 0 {
+		comma = p.Comma
+	}
+
+	files := make(map[string][]byte, len(tables))
+
+	for _, t := range tables {
+		if t.records == nil {
+			continue
+		}
+
+		var buffer bytes.Buffer
+
+		writer := csv.NewWriter(&buffer)
+		writer.Comma = comma
+		writer.UseCRLF = true
+
+		err := writer.WriteAll(t.records)
+		if err != nil {
+			return nil, fmt.Errorf("can`t write table %s: %w", t.name, err)
+		}
+
+		files[FileName(t.name, comma)] = buffer.Bytes()
+	}
+
+	return files, nil
+}
+
+// generalInfo возвращает таблицу общей информации, в которой каждому файлу соответствует отдельная строка.
+func generalInfo(rep *report.Report) table {
+	records := [][]string{{mutils.Header1GeneralInfo, mutils.Header2GeneralInfo}}
+
+	for _, file := range rep.Files {
+		records = append(records, []string{mutils.Row1GeneralInfo, file})
+	}
+
+	records = append(records,
+		[]string{mutils.Row2GeneralInfo, rep.From},
+		[]string{mutils.Row3GeneralInfo, rep.To},
+		[]string{mutils.Row4GeneralInfo, rep.Filter},
+		[]string{mutils.Row5GeneralInfo, strconv.Itoa(rep.RequestsCount)},
+		[]string{mutils.Row6GeneralInfo, strconv.FormatFloat(rep.AverageResponseSize,
+			mutils.FloatFormat, mutils.Prec, mutils.BitSize)},
+		[]string{mutils.Row7GeneralInfo, strconv.FormatFloat(rep.Percentile95ResponseSize,
+			mutils.FloatFormat, mutils.Prec, mutils.BitSize)},
+	)
+
+	return table{TableGeneralInfo, records}
+}
+
+// formats возвращает таблицу форматов файлов, если формат определялся для каждого файла.
+func formats(rep *report.Report) table {
+	if len(rep.FileFormats) == 0 && len(rep.UnrecognizedFiles) == 0 {
+		return table{name: TableFormats}
+	}
+
+	records := [][]string{{mutils.Header1Formats, mutils.Header2Formats}}
+
+	for _, fileFormat := range rep.FileFormats {
+		records = append(records, []string{fileFormat.File, fileFormat.Format})
+	}
+
+	for _, file := range rep.UnrecognizedFiles {
+		records = append(records, []string{file, mutils.UnrecognizedFormat})
+	}
+
+	return table{TableFormats, records}
+}
+
+// malformed возвращает таблицу некорректных строк, если такие строки были пропущены.
+func malformed(rep *report.Report) table {
+	if len(rep.MalformedLines) == 0 {
+		return table{name: TableMalformed}
+	}
+
+	records := [][]string{{mutils.Header1Malformed, mutils.Header2Malformed, mutils.Header3Malformed}}
+
+	for _, m := range rep.MalformedLines {
+		records = append(records, []string{m.File, mutils.GetMalformedKindName(m.Kind), strconv.Itoa(m.Count)})
+	}
+
+	return table{TableMalformed, records}
+}
+
+// latency возвращает таблицу времени обработки запросов, если оно известно,
+// со статистикой всех запросов и первых highest запрашиваемых ресурсов.
+func latency(rep *report.Report, highest int) table {
+	if rep.Latency.Count == 0 {
+		return table{name: TableLatency}
+	}
+
+	records := [][]string{mutils.GetLatencyHeaders(), mutils.GetLatencyRow(mutils.AllResources, &rep.Latency)}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.ResourceLatencies) && i < highest; i++ {
+		records = append(records, mutils.GetLatencyRow(rep.ResourceLatencies[i].Resource, &rep.ResourceLatencies[i].Latency))
+	}
+
+	return table{TableLatency, records}
+}
+
+// buckets возвращает таблицу запросов по интервалам времени, если они заданы.
+func buckets(rep *report.Report) table {
+	if len(rep.Buckets) == 0 {
+		return table{name: TableBuckets}
+	}
+
+	withLatency := mutils.HasBucketLatency(rep)
+	records := [][]string{mutils.GetBucketHeaders(withLatency)}
+
+	for i := range rep.Buckets {
+		records = append(records, mutils.GetBucketRow(&rep.Buckets[i], withLatency))
+	}
+
+	return table{TableBuckets, records}
+}
+
+// resources возвращает таблицу первых highest запрашиваемых ресурсов.
+func resources(rep *report.Report, highest int) table {
+	return counts(TableResources, mutils.Header1Resources, mutils.Header2Resources, rep.MostFrequentResources, highest)
+}
+
+// codes возвращает таблицу первых highest кодов ответа.
+func codes(rep *report.Report, highest int) table {
+	records := [][]string{{mutils.Header1Codes, mutils.Header2Codes, mutils.Header3Codes}}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.MostFrequentCodes) && i < highest; i++ {
+		records = append(records, []string{
+			strconv.Itoa(rep.MostFrequentCodes[i].Data),
+			http.StatusText(rep.MostFrequentCodes[i].Data),
+			strconv.Itoa(rep.MostFrequentCodes[i].Count),
+		})
+	}
+
+	return table{TableCodes, records}
+}
+
+// clients возвращает таблицу первых highest ip-адресов клиентов.
+func clients(rep *report.Report, highest int) table {
+	return counts(TableClients, mutils.Header1Clients, mutils.Header2Clients, rep.MostFrequentClients, highest)
+}
+
+// agents возвращает таблицу первых highest HTTP-заголовков User-Agent.
+func agents(rep *report.Report, highest int) table {
+	return counts(TableAgents, mutils.Header1Agents, mutils.Header2Agents, rep.MostFrequentAgents, highest)
+}
+
+// counts возвращает таблицу name со столбцами header1 и header2 из первых highest значений values.
+func counts(name, header1, header2 string, values []report.DataWithCount[string], highest int) table {
+	records := [][]string{{header1, header2}}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(values) && i < highest; i++ {
+		records = append(records, []string{values[i].Data, strconv.Itoa(values[i].Count)})
+	}
+
+	return table{name, records}
+}
+
+// FileName возвращает имя файла таблицы name, размеченной маркером с разделителем comma.
+func FileName(name string, comma rune) string {
+	if comma == '\t' {
+		return name + ".tsv"
+	}
+
+	return name + ".csv"
+}
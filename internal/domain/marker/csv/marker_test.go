@@ -0,0 +1,91 @@
+package csv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/csv"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markUp возвращает содержимое файлов таблиц отчёта rep, размеченных маркером m, по их именам.
+func markUp(t *testing.T, m *csv.Marker, rep *report.Report, highest int) map[string]string {
+	t.Helper()
+
+	tables, err := m.MarkUpTables(rep, highest)
+	require.NoError(t, err)
+
+	files := make(map[string]string, len(tables))
+	for name, content := range tables {
+		files[name] = string(content)
+	}
+
+	return files
+}
+
+func TestMarkUpTables(t *testing.T) {
+	rep := report.Report{
+		Files:         []string{"logs/a,b.txt", "logs/c.txt"},
+		From:          "2024-11-07T16:07:00Z",
+		To:            "-",
+		Filter:        `http_user_agent =~ "Opera"`,
+		RequestsCount: 10,
+		MostFrequentResources: []report.DataWithCount[string]{
+			{Data: "/search?q=a,b", Count: 6}, {Data: "/a", Count: 3}, {Data: "/b", Count: 1},
+		},
+		MostFrequentCodes:   []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 404, Count: 3}},
+		MostFrequentClients: []report.DataWithCount[string]{{Data: "70.27.134.194", Count: 10}},
+		MostFrequentAgents: []report.DataWithCount[string]{
+			{Data: `Mozilla/5.0 (X11; Linux x86_64) "Opera", 8`, Count: 10},
+		},
+		AverageResponseSize: 1373.5,
+		BucketSize:          time.Minute,
+		Buckets: []report.Bucket{
+			{
+				Start:         time.Date(2024, time.November, 7, 16, 7, 0, 0, time.UTC),
+				RequestsCount: 10,
+				StatusClasses: []int{0, 7, 0, 3, 0},
+				BytesSent:     13735,
+			},
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		files := markUp(t, &csv.Marker{}, &rep, 2)
+
+		assert.Len(t, files, 6)
+
+		for _, name := range []string{"general.csv", "buckets.csv", "resources.csv", "codes.csv", "clients.csv", "agents.csv"} {
+			assert.Contains(t, files, name)
+		}
+		assert.Equal(t, "Метрика,Значение\r\n"+
+			"Файл(-ы),\"logs/a,b.txt\"\r\n"+
+			"Файл(-ы),logs/c.txt\r\n"+
+			"Начальная дата,2024-11-07T16:07:00Z\r\n"+
+			"Конечная дата,-\r\n"+
+			"Фильтр,\"http_user_agent =~ \"\"Opera\"\"\"\r\n"+
+			"Количество запросов,10\r\n"+
+			"Средний размер ответа,1373.5\r\n"+
+			"95p размера ответа,0\r\n", files["general.csv"])
+		assert.Equal(t, "Начало,Запросы,1xx,2xx,3xx,4xx,5xx,Байты\r\n"+
+			"2024-11-07 16:07 Z,10,0,7,0,3,0,13735\r\n", files["buckets.csv"])
+		assert.Equal(t, "Ресурс,Количество\r\n\"/search?q=a,b\",6\r\n/a,3\r\n", files["resources.csv"])
+		assert.Equal(t, "Код,Имя,Количество\r\n200,OK,7\r\n404,Not Found,3\r\n", files["codes.csv"])
+		assert.Equal(t, "Агент,Количество\r\n\"Mozilla/5.0 (X11; Linux x86_64) \"\"Opera\"\", 8\",10\r\n", files["agents.csv"])
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		files := markUp(t, &csv.Marker{Comma: '\t'}, &rep, 1)
+
+		assert.Contains(t, files, "agents.tsv")
+		assert.Equal(t, "Ресурс\tКоличество\r\n/search?q=a,b\t6\r\n", files["resources.tsv"])
+		assert.Equal(t, "Агент\tКоличество\r\n\"Mozilla/5.0 (X11; Linux x86_64) \"\"Opera\"\", 8\"\t10\r\n", files["agents.tsv"])
+	})
+
+	t.Run("invalid separator", func(t *testing.T) {
+		_, err := (&csv.Marker{Comma: '"'}).MarkUpTables(&rep, 1)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,183 @@
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// Размеры диаграмм в пикселях.
+const (
+	chartWidth     = 720 // Ширина диаграмм.
+	lineHeight     = 260 // Высота графика.
+	linePadding    = 48  // Отступ области построения графика от краёв.
+	barRowHeight   = 34  // Высота строки гистограммы с подписью и столбцом.
+	barHeight      = 14  // Высота столбца гистограммы.
+	barLabelLength = 90  // Максимальное количество символов подписи столбца гистограммы.
+	pieRadius      = 100 // Радиус круговой диаграммы.
+	legendRow      = 22  // Высота строки легенды круговой диаграммы.
+)
+
+// palette - цвета частей диаграмм.
+var palette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+// markUpLineChart размечает svg-график количества запросов по интервалам времени buckets.
+// Точки располагаются по горизонтали пропорционально времени начала интервалов.
+func markUpLineChart(builder *strings.Builder, buckets []report.Bucket) {
+	maxCount := 0
+
+	for _, bucket := range buckets {
+		maxCount = max(maxCount, bucket.RequestsCount)
+	}
+
+	first, last := buckets[0].Start, buckets[len(buckets)-1].Start
+	plotWidth := float64(chartWidth - 2*linePadding)
+	plotHeight := float64(lineHeight - 2*linePadding)
+	points := make([]string, len(buckets))
+
+	var circles strings.Builder // Отметки точек графика.
+
+	for i, bucket := range buckets {
+		x := float64(linePadding) + plotWidth/2
+
+		if span := last.Sub(first); span > 0 {
+			x = float64(linePadding) + plotWidth*float64(bucket.Start.Sub(first))/float64(span)
+		}
+
+		y := float64(linePadding) + plotHeight*(1-float64(bucket.RequestsCount)/float64(max(maxCount, 1)))
+		points[i] = formatCoordinate(x) + "," + formatCoordinate(y)
+
+		fmt.Fprintf(&circles, "<circle cx=\"%s\" cy=\"%s\" r=\"3\" fill=\"%s\"/>\n",
+			formatCoordinate(x), formatCoordinate(y), palette[0])
+	}
+
+	bottom := lineHeight - linePadding
+
+	markUpSVGHeader(builder, chartWidth, lineHeight, mutils.TitleBuckets)
+	fmt.Fprintf(builder, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#999\"/>\n",
+		linePadding, linePadding, linePadding, bottom)
+	fmt.Fprintf(builder, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#999\"/>\n",
+		linePadding, bottom, chartWidth-linePadding, bottom)
+	fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\" text-anchor=\"end\">%d</text>\n", linePadding-6, linePadding+4, maxCount)
+	fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\" text-anchor=\"end\">0</text>\n", linePadding-6, bottom+4)
+	fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\">%s</text>\n", linePadding, bottom+20,
+		template.HTMLEscapeString(first.Format(mutils.BucketTimeLayout)))
+	fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\" text-anchor=\"end\">%s</text>\n", chartWidth-linePadding, bottom+20,
+		template.HTMLEscapeString(last.Format(mutils.BucketTimeLayout)))
+	fmt.Fprintf(builder, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n",
+		strings.Join(points, " "), palette[0])
+	builder.WriteString(circles.String())
+	builder.WriteString("</svg>\n")
+}
+
+// markUpBarChart размечает svg-гистограмму количества значений values. Каждый столбец подписан значением и количеством.
+func markUpBarChart(builder *strings.Builder, values []report.DataWithCount[string]) {
+	if len(values) == 0 {
+		return
+	}
+
+	maxCount := 1
+
+	for _, value := range values {
+		maxCount = max(maxCount, value.Count)
+	}
+
+	barWidth := float64(chartWidth - 80) // Место справа от самого длинного столбца занимает количество.
+
+	markUpSVGHeader(builder, chartWidth, barRowHeight*len(values)+4, mutils.TitleResources)
+
+	for i, value := range values {
+		y := barRowHeight * i
+
+		fmt.Fprintf(builder, "<text x=\"0\" y=\"%d\">%s</text>\n", y+12, template.HTMLEscapeString(truncate(value.Data)))
+
+		width := barWidth * float64(value.Count) / float64(maxCount)
+
+		fmt.Fprintf(builder, "<rect x=\"0\" y=\"%d\" width=\"%s\" height=\"%d\" fill=\"%s\"/>\n",
+			y+16, formatCoordinate(width), barHeight, palette[i%len(palette)])
+		fmt.Fprintf(builder, "<text x=\"%s\" y=\"%d\">%d</text>\n", formatCoordinate(width+6), y+28, value.Count)
+	}
+
+	builder.WriteString("</svg>\n")
+}
+
+// markUpPieChart размечает svg-диаграмму долей parts с легендой, содержащей количество и процент каждой части.
+func markUpPieChart(builder *strings.Builder, parts []report.DataWithCount[string]) {
+	total := 0
+
+	for _, part := range parts {
+		total += part.Count
+	}
+
+	if total == 0 {
+		return
+	}
+
+	height := max(2*pieRadius+8, legendRow*len(parts)+8)
+	cx, cy := float64(pieRadius+4), float64(pieRadius+4)
+	angle := -math.Pi / 2 // Части откладываются по часовой стрелке от вершины круга.
+
+	markUpSVGHeader(builder, chartWidth, height, mutils.TitleCodes)
+
+	for i, part := range parts {
+		color := palette[i%len(palette)]
+
+		if part.Count == total {
+			fmt.Fprintf(builder, "<circle cx=\"%s\" cy=\"%s\" r=\"%d\" fill=\"%s\"/>\n",
+				formatCoordinate(cx), formatCoordinate(cy), pieRadius, color)
+		} else if part.Count != 0 {
+			next := angle + 2*math.Pi*float64(part.Count)/float64(total)
+
+			largeArc := 0
+			if next-angle > math.Pi {
+				largeArc = 1
+			}
+
+			fmt.Fprintf(builder, "<path d=\"M%s,%s L%s,%s A%d,%d 0 %d 1 %s,%s Z\" fill=\"%s\"/>\n",
+				formatCoordinate(cx), formatCoordinate(cy),
+				formatCoordinate(cx+pieRadius*math.Cos(angle)), formatCoordinate(cy+pieRadius*math.Sin(angle)),
+				pieRadius, pieRadius, largeArc,
+				formatCoordinate(cx+pieRadius*math.Cos(next)), formatCoordinate(cy+pieRadius*math.Sin(next)),
+				color)
+
+			angle = next
+		}
+
+		y := legendRow*i + 8
+		percent := 100 * float64(part.Count) / float64(total)
+
+		fmt.Fprintf(builder, "<rect x=\"%d\" y=\"%d\" width=\"14\" height=\"14\" fill=\"%s\"/>\n", 2*pieRadius+32, y, color)
+		fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\">%s: %d (%s%%)</text>\n", 2*pieRadius+54, y+12,
+			template.HTMLEscapeString(part.Data), part.Count, strconv.FormatFloat(percent, 'f', 1, 64))
+	}
+
+	builder.WriteString("</svg>\n")
+}
+
+// markUpSVGHeader размечает начало svg-диаграммы размером width на height с доступным названием title.
+func markUpSVGHeader(builder *strings.Builder, width, height int, title string) {
+	fmt.Fprintf(builder, "<svg width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" "+
+		"role=\"img\" aria-label=\"%s\">\n", width, height, width, height, template.HTMLEscapeString(title))
+}
+
+// formatCoordinate возвращает строковое представление координаты с точностью до десятой пикселя.
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+// truncate сокращает подпись до barLabelLength символов.
+func truncate(label string) string {
+	runes := []rune(label)
+	if len(runes) <= barLabelLength {
+		return label
+	}
+
+	return string(runes[:barLabelLength-1]) + "…"
+}
@@ -0,0 +1,248 @@
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// style - встроенные стили страницы отчёта. Отчёт не ссылается на внешние ресурсы и открывается без сети.
+const style = `body{font-family:-apple-system,"Segoe UI",Roboto,Helvetica,Arial,sans-serif;margin:2em auto;max-width:1100px;` +
+	`padding:0 1em;color:#222}
+h1{font-size:1.6em}
+h2{font-size:1.25em;margin-top:2em;border-bottom:1px solid #ddd;padding-bottom:.3em}
+table{border-collapse:collapse;margin:1em 0}
+th,td{border:1px solid #ddd;padding:.35em .7em;text-align:center;white-space:pre-line;overflow-wrap:anywhere}
+th{background:#f4f4f4}
+tr:nth-child(even) td{background:#fafafa}
+svg{display:block;margin:1em 0;font-size:12px}
+svg text{fill:#333}
+`
+
+// Marker умеет размечать отчёт в соответствии с html, дополняя таблицы svg-диаграммами.
+type Marker struct{}
+
+// MarkUp размечает отчёт, используя html, записывая первые highest значений таблиц, не содержащих общую информацию.
+// Результат - самодостаточная страница со встроенными стилями и диаграммами, не использующая JavaScript.
+func (p *Marker) MarkUp(rep *report.Report, highest int) string {
+	var builder strings.Builder
+
+	builder.WriteString("<!DOCTYPE html>\n<html lang=\"ru\">\n<head>\n<meta charset=\"utf-8\"/>\n")
+	fmt.Fprintf(&builder, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", mutils.TitleReport, style)
+	fmt.Fprintf(&builder, "<h1>%s</h1>\n", mutils.TitleReport)
+
+	markUpGeneralInfo(&builder, rep)
+	markUpFormats(&builder, rep)
+	markUpMalformed(&builder, rep)
+	markUpLatency(&builder, rep, highest)
+	markUpBuckets(&builder, rep)
+	markUpResources(&builder, rep, highest)
+	markUpCodes(&builder, rep, highest)
+	markUpClients(&builder, rep, highest)
+	markUpAgents(&builder, rep, highest)
+
+	builder.WriteString("</body>\n</html>\n")
+
+	return builder.String()
+}
+
+// markUpGeneralInfo размечает заголовок и таблицу общей информации.
+func markUpGeneralInfo(builder *strings.Builder, rep *report.Report) {
+	markUpTitle(builder, mutils.TitleGeneralInfo)
+	markUpTableHeader(builder, mutils.Header1GeneralInfo, mutils.Header2GeneralInfo)
+	markUpTableRow(builder, mutils.Row1GeneralInfo, strings.Join(rep.Files, "\n"))
+	markUpTableRow(builder, mutils.Row2GeneralInfo, rep.From)
+	markUpTableRow(builder, mutils.Row3GeneralInfo, rep.To)
+	markUpTableRow(builder, mutils.Row4GeneralInfo, rep.Filter)
+	markUpTableRow(builder, mutils.Row5GeneralInfo, strconv.Itoa(rep.RequestsCount))
+	markUpTableRow(builder, mutils.Row6GeneralInfo, strconv.FormatFloat(rep.AverageResponseSize,
+		mutils.FloatFormat, mutils.Prec, mutils.BitSize))
+	markUpTableRow(builder, mutils.Row7GeneralInfo, strconv.FormatFloat(rep.Percentile95ResponseSize,
+		mutils.FloatFormat, mutils.Prec, mutils.BitSize))
+	markUpTableFooter(builder)
+}
+
+// markUpFormats размечает заголовок и таблицу форматов файлов, если формат определялся для каждого файла.
+func markUpFormats(builder *strings.Builder, rep *report.Report) {
+	if len(rep.FileFormats) == 0 && len(rep.UnrecognizedFiles) == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleFormats)
+	markUpTableHeader(builder, mutils.Header1Formats, mutils.Header2Formats)
+
+	for _, fileFormat := range rep.FileFormats {
+		markUpTableRow(builder, fileFormat.File, fileFormat.Format)
+	}
+
+	for _, file := range rep.UnrecognizedFiles {
+		markUpTableRow(builder, file, mutils.UnrecognizedFormat)
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpMalformed размечает заголовок и таблицу некорректных строк, если такие строки были пропущены.
+func markUpMalformed(builder *strings.Builder, rep *report.Report) {
+	if len(rep.MalformedLines) == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleMalformed)
+	markUpTableHeader(builder, mutils.Header1Malformed, mutils.Header2Malformed, mutils.Header3Malformed)
+
+	for _, malformed := range rep.MalformedLines {
+		markUpTableRow(builder, malformed.File, mutils.GetMalformedKindName(malformed.Kind), strconv.Itoa(malformed.Count))
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpLatency размечает заголовок и таблицу времени обработки запросов, если оно известно,
+// записывая статистику всех запросов и первых highest запрашиваемых ресурсов.
+func markUpLatency(builder *strings.Builder, rep *report.Report, highest int) {
+	if rep.Latency.Count == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleLatency)
+	markUpTableHeader(builder, mutils.GetLatencyHeaders()...)
+	markUpTableRow(builder, mutils.GetLatencyRow(mutils.AllResources, &rep.Latency)...)
+
+	// Размечаются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.ResourceLatencies) && i < highest; i++ {
+		markUpTableRow(builder, mutils.GetLatencyRow(rep.ResourceLatencies[i].Resource, &rep.ResourceLatencies[i].Latency)...)
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpBuckets размечает заголовок, график и таблицу запросов по интервалам времени, если они заданы.
+func markUpBuckets(builder *strings.Builder, rep *report.Report) {
+	if len(rep.Buckets) == 0 {
+		return
+	}
+
+	withLatency := mutils.HasBucketLatency(rep)
+
+	markUpTitle(builder, mutils.TitleBuckets)
+	markUpLineChart(builder, rep.Buckets)
+	markUpTableHeader(builder, mutils.GetBucketHeaders(withLatency)...)
+
+	for i := range rep.Buckets {
+		markUpTableRow(builder, mutils.GetBucketRow(&rep.Buckets[i], withLatency)...)
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpResources размечает заголовок, гистограмму и таблицу заправшиваемых ресурсов.
+func markUpResources(builder *strings.Builder, rep *report.Report, highest int) {
+	resources := rep.MostFrequentResources[:min(highest, len(rep.MostFrequentResources))]
+
+	markUpTitle(builder, mutils.TitleResources)
+	markUpBarChart(builder, resources)
+	markUpTableHeader(builder, mutils.Header1Resources, mutils.Header2Resources)
+
+	for _, resource := range resources {
+		markUpTableRow(builder, resource.Data, strconv.Itoa(resource.Count))
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpCodes размечает заголовок, круговую диаграмму и таблицу кодов ответа.
+// Диаграмма показывает первые highest кодов и суммарную долю остальных.
+func markUpCodes(builder *strings.Builder, rep *report.Report, highest int) {
+	codes := rep.MostFrequentCodes[:min(highest, len(rep.MostFrequentCodes))]
+	parts := make([]report.DataWithCount[string], 0, len(codes)+1)
+	rest := 0
+
+	for i, code := range rep.MostFrequentCodes {
+		if i < len(codes) {
+			parts = append(parts, report.DataWithCount[string]{
+				Data:  strconv.Itoa(code.Data) + " " + http.StatusText(code.Data),
+				Count: code.Count,
+			})
+		} else {
+			rest += code.Count
+		}
+	}
+
+	if rest != 0 {
+		parts = append(parts, report.DataWithCount[string]{Data: mutils.OtherCodes, Count: rest})
+	}
+
+	markUpTitle(builder, mutils.TitleCodes)
+	markUpPieChart(builder, parts)
+	markUpTableHeader(builder, mutils.Header1Codes, mutils.Header2Codes, mutils.Header3Codes)
+
+	for _, code := range codes {
+		markUpTableRow(builder, strconv.Itoa(code.Data), http.StatusText(code.Data), strconv.Itoa(code.Count))
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpClients размечает заголовок и таблицу ip-адресов клиентов.
+func markUpClients(builder *strings.Builder, rep *report.Report, highest int) {
+	markUpTitle(builder, mutils.TitleClients)
+	markUpTableHeader(builder, mutils.Header1Clients, mutils.Header2Clients)
+
+	// Размечаются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.MostFrequentClients) && i < highest; i++ {
+		markUpTableRow(builder, rep.MostFrequentClients[i].Data, strconv.Itoa(rep.MostFrequentClients[i].Count))
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpAgents размечает заголовок и таблицу HTTP-заголовков User-Agent.
+func markUpAgents(builder *strings.Builder, rep *report.Report, highest int) {
+	markUpTitle(builder, mutils.TitleAgents)
+	markUpTableHeader(builder, mutils.Header1Agents, mutils.Header2Agents)
+
+	// Размечаются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.MostFrequentAgents) && i < highest; i++ {
+		markUpTableRow(builder, rep.MostFrequentAgents[i].Data, strconv.Itoa(rep.MostFrequentAgents[i].Count))
+	}
+
+	markUpTableFooter(builder)
+}
+
+// markUpTitle размечает заголовок второго уровня в html.
+func markUpTitle(builder *strings.Builder, name string) {
+	fmt.Fprintf(builder, "<h2>%s</h2>\n", template.HTMLEscapeString(name))
+}
+
+// markUpTableHeader размечает начало таблицы и строку заголовков в html.
+func markUpTableHeader(builder *strings.Builder, headers ...string) {
+	builder.WriteString("<table>\n<tr>")
+
+	for _, header := range headers {
+		fmt.Fprintf(builder, "<th>%s</th>", template.HTMLEscapeString(header))
+	}
+
+	builder.WriteString("</tr>\n")
+}
+
+// markUpTableRow размечает строку таблицы в html, экранируя значения ячеек.
+func markUpTableRow(builder *strings.Builder, cells ...string) {
+	builder.WriteString("<tr>")
+
+	for _, cell := range cells {
+		fmt.Fprintf(builder, "<td>%s</td>", template.HTMLEscapeString(cell))
+	}
+
+	builder.WriteString("</tr>\n")
+}
+
+// markUpTableFooter размечает конец таблицы в html.
+func markUpTableFooter(builder *strings.Builder) {
+	builder.WriteString("</table>\n")
+}
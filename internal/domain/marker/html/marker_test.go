@@ -0,0 +1,94 @@
+package html_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/html"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkUp(t *testing.T) {
+	rep := report.Report{
+		Files:                 []string{"logs/a.txt", "logs/b.txt"},
+		From:                  "2024-11-07T16:07:00Z",
+		To:                    "-",
+		Filter:                `status >= 500 && http_user_agent =~ "<script>"`,
+		RequestsCount:         10,
+		MostFrequentResources: []report.DataWithCount[string]{{Data: "/core.svg", Count: 6}, {Data: "/a?b=<c>&d", Count: 4}},
+		MostFrequentCodes:     []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 404, Count: 2}, {Data: 500, Count: 1}},
+		MostFrequentClients:   []report.DataWithCount[string]{{Data: "70.27.134.194", Count: 10}},
+		MostFrequentAgents:    []report.DataWithCount[string]{{Data: "<b>Opera</b>", Count: 10}},
+		AverageResponseSize:   1373,
+		BucketSize:            time.Minute,
+		Buckets: []report.Bucket{
+			{
+				Start:         time.Date(2024, time.November, 7, 16, 7, 0, 0, time.UTC),
+				RequestsCount: 4,
+				StatusClasses: []int{0, 4, 0, 0, 0},
+			},
+			{
+				Start:         time.Date(2024, time.November, 7, 16, 9, 0, 0, time.UTC),
+				RequestsCount: 6,
+				StatusClasses: []int{0, 3, 0, 2, 1},
+			},
+		},
+	}
+
+	got := (&html.Marker{}).MarkUp(&rep, 2)
+
+	// Страница является корректным XML, поэтому все теги закрыты, а значения экранированы.
+	decoder := xml.NewDecoder(strings.NewReader(strings.TrimPrefix(got, "<!DOCTYPE html>\n")))
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.True(t, strings.HasPrefix(got, "<!DOCTYPE html>\n<html lang=\"ru\">"))
+	assert.NotContains(t, got, "<script")
+	assert.NotContains(t, got, "http://")
+	assert.NotContains(t, got, "<b>")
+	assert.Contains(t, got, "<style>")
+	assert.Contains(t, got, "<td>Файл(-ы)</td><td>logs/a.txt\nlogs/b.txt</td>")
+	assert.Contains(t, got, "<td>status &gt;= 500 &amp;&amp; http_user_agent =~ &#34;&lt;script&gt;&#34;</td>")
+	assert.Contains(t, got, "<td>&lt;b&gt;Opera&lt;/b&gt;</td><td>10</td>")
+
+	// График запросов по времени.
+	assert.Contains(t, got, `<polyline points="48.0,102.7 672.0,48.0"`)
+
+	// Гистограмма ресурсов.
+	assert.Contains(t, got, `<rect x="0" y="16" width="640.0" height="14" fill="#4e79a7"/>`)
+	assert.Contains(t, got, `<text x="0" y="46">/a?b=&lt;c&gt;&amp;d</text>`)
+
+	// Круговая диаграмма первых двух кодов ответа и остальных.
+	assert.Equal(t, 3, strings.Count(got, "<path d="))
+	assert.Contains(t, got, ">200 OK: 7 (70.0%)</text>")
+	assert.Contains(t, got, ">404 Not Found: 2 (20.0%)</text>")
+	assert.Contains(t, got, ">Прочие: 1 (10.0%)</text>")
+	assert.NotContains(t, got, "<td>500</td>")
+}
+
+func TestMarkUpSingleCode(t *testing.T) {
+	rep := report.Report{
+		Files:             []string{"logs.txt"},
+		RequestsCount:     3,
+		MostFrequentCodes: []report.DataWithCount[int]{{Data: 200, Count: 3}},
+	}
+
+	got := (&html.Marker{}).MarkUp(&rep, 3)
+
+	assert.Contains(t, got, `<circle cx="104.0" cy="104.0" r="100" fill="#4e79a7"/>`)
+	assert.NotContains(t, got, "<path d=")
+	assert.NotContains(t, got, "<polyline")
+}
@@ -0,0 +1,247 @@
+package json
+
+import (
+	_ "embed" // Схема отчёта встраивается в Schema.
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// Version - версия схемы отчёта. Увеличивается при несовместимых изменениях: удалении, переименовании ключей
+// или изменении их типов. Новые ключи добавляются без изменения версии.
+const Version = 1
+
+// Schema - JSON Schema отчёта версии Version.
+//
+//go:embed schema.json
+var Schema string
+
+// Marker умеет размечать отчёт в соответствии с JSON.
+type Marker struct{}
+
+// MarkUp размечает отчёт, используя JSON, записывая первые highest значений списков запрашиваемых ресурсов,
+// кодов ответа, ip-адресов клиентов, HTTP-заголовков User-Agent и времени обработки запросов к ресурсам.
+// Ключи объектов записываются в порядке, заданном схемой Schema.
+func (p *Marker) MarkUp(rep *report.Report, highest int) string {
+	doc := document{
+		Version:                  Version,
+		Files:                    orEmpty(rep.Files),
+		From:                     optional(rep.From),
+		To:                       optional(rep.To),
+		Filter:                   optional(rep.Filter),
+		RequestsCount:            rep.RequestsCount,
+		AverageResponseSize:      finite(rep.AverageResponseSize),
+		Percentile95ResponseSize: finite(rep.Percentile95ResponseSize),
+		FileFormats:              make([]fileFormat, 0, len(rep.FileFormats)),
+		UnrecognizedFiles:        orEmpty(rep.UnrecognizedFiles),
+		MalformedLines:           make([]malformedLines, 0, len(rep.MalformedLines)),
+		Latency:                  newLatency(&rep.Latency),
+		ResourceLatencies:        make([]resourceLatency, 0, min(len(rep.ResourceLatencies), highest)),
+		BucketSeconds:            rep.BucketSize.Seconds(),
+		Buckets:                  make([]bucket, 0, len(rep.Buckets)),
+		Resources:                newCounts(rep.MostFrequentResources, highest),
+		Codes:                    make([]code, 0, min(len(rep.MostFrequentCodes), highest)),
+		Clients:                  newCounts(rep.MostFrequentClients, highest),
+		Agents:                   newCounts(rep.MostFrequentAgents, highest),
+	}
+
+	for _, ff := range rep.FileFormats {
+		doc.FileFormats = append(doc.FileFormats, fileFormat(ff))
+	}
+
+	for _, malformed := range rep.MalformedLines {
+		doc.MalformedLines = append(doc.MalformedLines, malformedLines(malformed))
+	}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.ResourceLatencies) && i < highest; i++ {
+		doc.ResourceLatencies = append(doc.ResourceLatencies, resourceLatency{
+			Resource: rep.ResourceLatencies[i].Resource,
+			Latency:  newLatency(&rep.ResourceLatencies[i].Latency),
+		})
+	}
+
+	for i := range rep.Buckets {
+		doc.Buckets = append(doc.Buckets, newBucket(&rep.Buckets[i]))
+	}
+
+	for i := 0; i < len(rep.MostFrequentCodes) && i < highest; i++ {
+		doc.Codes = append(doc.Codes, code{
+			Value:       rep.MostFrequentCodes[i].Data,
+			Description: http.StatusText(rep.MostFrequentCodes[i].Data),
+			Count:       rep.MostFrequentCodes[i].Count,
+		})
+	}
+
+	// Нечисловые значения заменяются null функцией finite, поэтому ошибка возможна только при изменении документа.
+	markup, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(markup) + "\n"
+}
+
+// document - отчёт в формате JSON. Порядок полей задаёт порядок ключей.
+type document struct {
+	Version                  int               `json:"version"`
+	Files                    []string          `json:"files"`
+	From                     *string           `json:"from"`
+	To                       *string           `json:"to"`
+	Filter                   *string           `json:"filter"`
+	RequestsCount            int               `json:"requests_count"`
+	AverageResponseSize      *float64          `json:"average_response_size"`
+	Percentile95ResponseSize *float64          `json:"percentile95_response_size"`
+	FileFormats              []fileFormat      `json:"file_formats"`
+	UnrecognizedFiles        []string          `json:"unrecognized_files"`
+	MalformedLines           []malformedLines  `json:"malformed_lines"`
+	Latency                  *latency          `json:"latency"`
+	ResourceLatencies        []resourceLatency `json:"resource_latencies"`
+	BucketSeconds            float64           `json:"bucket_seconds"`
+	Buckets                  []bucket          `json:"buckets"`
+	Resources                []count           `json:"resources"`
+	Codes                    []code            `json:"codes"`
+	Clients                  []count           `json:"clients"`
+	Agents                   []count           `json:"agents"`
+}
+
+// fileFormat - формат лога, определённый для файла.
+type fileFormat struct {
+	File   string `json:"file"`
+	Format string `json:"format"`
+}
+
+// malformedLines - количество некорректных строк файла с ошибкой разбора одного вида.
+type malformedLines struct {
+	File  string `json:"file"`
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// latency - статистика времени обработки запросов в секундах. Значения, не являющиеся конечными числами, равны nil.
+type latency struct {
+	Count       int          `json:"count"`
+	Min         *float64     `json:"min"`
+	Average     *float64     `json:"average"`
+	Max         *float64     `json:"max"`
+	Percentiles []percentile `json:"percentiles"`
+}
+
+// percentile - значение перцентиля ранга Rank.
+type percentile struct {
+	Rank  float64  `json:"rank"`
+	Value *float64 `json:"value"`
+}
+
+// resourceLatency - статистика времени обработки запросов к ресурсу.
+type resourceLatency struct {
+	Resource string   `json:"resource"`
+	Latency  *latency `json:"latency"`
+}
+
+// bucket - статистика запросов интервала времени.
+type bucket struct {
+	Start         string         `json:"start"`
+	RequestsCount int            `json:"requests_count"`
+	StatusClasses map[string]int `json:"status_classes"` // Ключи словаря записываются в порядке сортировки.
+	BytesSent     int            `json:"bytes_sent"`
+	Latency       *latency       `json:"latency"`
+}
+
+// count - количество запросов со значением Value.
+type count struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// code - количество запросов с кодом ответа Value.
+type code struct {
+	Value       int    `json:"value"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+}
+
+// optional возвращает указатель на value или nil, если значение не задано.
+func optional(value string) *string {
+	if value == mutils.NoValue {
+		return nil
+	}
+
+	return &value
+}
+
+// finite возвращает указатель на value или nil, если value не является конечным числом,
+// например средний размер ответа при отсутствии запросов.
+func finite(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+
+	return &value
+}
+
+// orEmpty возвращает values или пустой слайс, если values равен nil, чтобы он записывался как [], а не null.
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// newLatency возвращает статистику времени обработки запросов или nil, если оно неизвестно.
+func newLatency(lat *report.Latency) *latency {
+	if lat.Count == 0 {
+		return nil
+	}
+
+	percentiles := make([]percentile, 0, len(lat.Percentiles))
+
+	for i, value := range lat.Percentiles {
+		percentiles = append(percentiles, percentile{Rank: report.LatencyPercentiles[i], Value: finite(value)})
+	}
+
+	return &latency{
+		Count:       lat.Count,
+		Min:         finite(lat.Min),
+		Average:     finite(lat.Average),
+		Max:         finite(lat.Max),
+		Percentiles: percentiles,
+	}
+}
+
+// newBucket возвращает статистику запросов интервала времени b.
+func newBucket(b *report.Bucket) bucket {
+	classes := make(map[string]int, len(report.StatusClasses))
+
+	for i, class := range report.StatusClasses {
+		if i < len(b.StatusClasses) {
+			classes[class] = b.StatusClasses[i]
+		} else {
+			classes[class] = 0
+		}
+	}
+
+	return bucket{
+		Start:         b.Start.Format(time.RFC3339),
+		RequestsCount: b.RequestsCount,
+		StatusClasses: classes,
+		BytesSent:     b.BytesSent,
+		Latency:       newLatency(&b.Latency),
+	}
+}
+
+// newCounts возвращает первые highest значений values, или все, если highest больше их количества.
+func newCounts(values []report.DataWithCount[string], highest int) []count {
+	counts := make([]count, 0, min(len(values), highest))
+
+	for i := 0; i < len(values) && i < highest; i++ {
+		counts = append(counts, count{Value: values[i].Data, Count: values[i].Count})
+	}
+
+	return counts
+}
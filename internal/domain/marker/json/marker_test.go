@@ -0,0 +1,179 @@
+package json_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	markerjson "github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/json"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compileSchema компилирует схему отчёта.
+func compileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+
+	err := compiler.AddResource("report.schema.json", strings.NewReader(markerjson.Schema))
+	require.NoError(t, err)
+
+	schema, err := compiler.Compile("report.schema.json")
+	require.NoError(t, err)
+
+	return schema
+}
+
+func TestMarkUp(t *testing.T) {
+	latency := report.Latency{Count: 2, Min: 0.1, Average: 0.2, Max: 0.3, Percentiles: []float64{0.2, 0.3, 0.3, 0.3, 0.3}}
+
+	tests := []struct {
+		name    string
+		rep     report.Report
+		highest int
+		want    []string // Фрагменты, которые должен содержать отчёт.
+	}{
+		{
+			name: "full report",
+			rep: report.Report{
+				Files:         []string{"logs/a.txt"},
+				From:          "2024-11-07T16:07:00Z",
+				To:            "-",
+				Filter:        `http_user_agent =~ "\"Opera\", 8"`,
+				RequestsCount: 10,
+				MostFrequentResources: []report.DataWithCount[string]{
+					{Data: "/core.svg", Count: 6}, {Data: "/a", Count: 3}, {Data: "/b", Count: 1},
+				},
+				MostFrequentCodes:        []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 499, Count: 3}},
+				MostFrequentClients:      []report.DataWithCount[string]{{Data: "70.27.134.194", Count: 10}},
+				MostFrequentAgents:       []report.DataWithCount[string]{{Data: `"Opera", 8`, Count: 10}},
+				AverageResponseSize:      1373.5,
+				Percentile95ResponseSize: 2048,
+				FileFormats:              []report.FileFormat{{File: "logs/a.txt", Format: "nginx"}},
+				UnrecognizedFiles:        []string{"logs/b.txt"},
+				MalformedLines:           []report.MalformedLines{{File: "logs/a.txt", Kind: report.MalformedTime, Count: 2}},
+				Latency:                  latency,
+				ResourceLatencies:        []report.ResourceLatency{{Resource: "/core.svg", Latency: latency}},
+				BucketSize:               time.Minute,
+				Buckets: []report.Bucket{
+					{
+						Start:         time.Date(2024, time.November, 7, 16, 7, 0, 0, time.FixedZone("", 3*60*60)),
+						RequestsCount: 10,
+						StatusClasses: []int{0, 7, 0, 3, 0},
+						BytesSent:     13735,
+						Latency:       latency,
+					},
+				},
+			},
+			highest: math.MaxInt,
+			want: []string{
+				`"version": 1`,
+				`"from": "2024-11-07T16:07:00Z"`,
+				`"to": null`,
+				`"filter": "http_user_agent =~ \"\\\"Opera\\\", 8\""`,
+				`"value": "\"Opera\", 8"`,
+				`"bucket_seconds": 60`,
+				`"start": "2024-11-07T16:07:00+03:00"`,
+				`"value": "/b"`,
+				`"description": ""`,
+			},
+		},
+		{
+			name: "truncated report",
+			rep: report.Report{
+				From:   "-",
+				To:     "-",
+				Filter: "-",
+				MostFrequentResources: []report.DataWithCount[string]{
+					{Data: "/core.svg", Count: 6}, {Data: "/a", Count: 3}, {Data: "/b", Count: 1},
+				},
+				AverageResponseSize: math.NaN(),
+			},
+			highest: 2,
+			want: []string{
+				`"files": []`,
+				`"filter": null`,
+				`"average_response_size": null`,
+				`"latency": null`,
+				`"value": "/a"`,
+			},
+		}, {
+			name: "non-finite latency",
+			rep: report.Report{
+				From:   "-",
+				To:     "-",
+				Filter: "-",
+				Latency: report.Latency{
+					Count: 1, Min: 0.1, Average: math.NaN(), Max: math.Inf(1), Percentiles: []float64{math.NaN(), 0.3},
+				},
+			},
+			highest: 1,
+			want: []string{
+				`"min": 0.1`,
+				`"average": null`,
+				`"max": null`,
+				`"value": null`,
+				`"value": 0.3`,
+			},
+		},
+	}
+
+	schema := compileSchema(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&markerjson.Marker{}).MarkUp(&tt.rep, tt.highest)
+
+			var doc any
+
+			require.NoError(t, json.Unmarshal([]byte(got), &doc))
+			require.NoError(t, schema.Validate(doc))
+
+			for _, fragment := range tt.want {
+				assert.Contains(t, got, fragment)
+			}
+
+			resources := doc.(map[string]any)["resources"].([]any)
+			assert.Len(t, resources, min(len(tt.rep.MostFrequentResources), tt.highest))
+		})
+	}
+}
+
+func TestMarkUpKeyOrder(t *testing.T) {
+	rep := report.Report{From: "-", To: "-", Filter: "-"}
+
+	got := (&markerjson.Marker{}).MarkUp(&rep, 1)
+
+	var schema struct {
+		Required []string `json:"required"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(markerjson.Schema), &schema))
+
+	// Ключи верхнего уровня записываются в порядке, перечисленном в схеме.
+	decoder := json.NewDecoder(strings.NewReader(got))
+	keys := []string{}
+
+	_, err := decoder.Token()
+	require.NoError(t, err)
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		require.NoError(t, err)
+
+		keys = append(keys, key.(string))
+
+		var value json.RawMessage
+
+		require.NoError(t, decoder.Decode(&value))
+	}
+
+	assert.Equal(t, schema.Required, keys)
+}
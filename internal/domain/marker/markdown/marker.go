@@ -20,6 +20,10 @@ func (p *Marker) MarkUp(rep *report.Report, highest int) string {
 	var builder strings.Builder
 
 	markUpGeneralInfo(&builder, rep)
+	markUpFormats(&builder, rep)
+	markUpMalformed(&builder, rep)
+	markUpLatency(&builder, rep, highest)
+	markUpBuckets(&builder, rep)
 	markUpResources(&builder, rep, highest)
 	markUpCodes(&builder, rep, highest)
 	markUpClients(&builder, rep, highest)
@@ -35,15 +39,79 @@ func markUpGeneralInfo(builder *strings.Builder, rep *report.Report) {
 	markUpTableRow(builder, mutils.Row1GeneralInfo, mutils.GetTableCellWithMultipleValues(rep.Files, separator))
 	markUpTableRow(builder, mutils.Row2GeneralInfo, rep.From)
 	markUpTableRow(builder, mutils.Row3GeneralInfo, rep.To)
-	markUpTableRow(builder, mutils.Row4GeneralInfo, rep.Field)
-	markUpTableRow(builder, mutils.Row5GeneralInfo, rep.Value)
-	markUpTableRow(builder, mutils.Row6GeneralInfo, strconv.Itoa(rep.RequestsCount))
-	markUpTableRow(builder, mutils.Row7GeneralInfo, strconv.FormatFloat(rep.AverageResponseSize,
+	markUpTableRow(builder, mutils.Row4GeneralInfo, rep.Filter)
+	markUpTableRow(builder, mutils.Row5GeneralInfo, strconv.Itoa(rep.RequestsCount))
+	markUpTableRow(builder, mutils.Row6GeneralInfo, strconv.FormatFloat(rep.AverageResponseSize,
 		mutils.FloatFormat, mutils.Prec, mutils.BitSize))
-	markUpTableRow(builder, mutils.Row8GeneralInfo, strconv.FormatFloat(rep.Percentile95ResponseSize,
+	markUpTableRow(builder, mutils.Row7GeneralInfo, strconv.FormatFloat(rep.Percentile95ResponseSize,
 		mutils.FloatFormat, mutils.Prec, mutils.BitSize))
 }
 
+// markUpFormats размечает заголовок и таблицу форматов файлов, если формат определялся для каждого файла.
+func markUpFormats(builder *strings.Builder, rep *report.Report) {
+	if len(rep.FileFormats) == 0 && len(rep.UnrecognizedFiles) == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleFormats)
+	markUpTableHeader(builder, mutils.Header1Formats, mutils.Header2Formats)
+
+	for _, fileFormat := range rep.FileFormats {
+		markUpTableRow(builder, fileFormat.File, fileFormat.Format)
+	}
+
+	for _, file := range rep.UnrecognizedFiles {
+		markUpTableRow(builder, file, mutils.UnrecognizedFormat)
+	}
+}
+
+// markUpMalformed размечает заголовок и таблицу некорректных строк, если такие строки были пропущены.
+func markUpMalformed(builder *strings.Builder, rep *report.Report) {
+	if len(rep.MalformedLines) == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleMalformed)
+	markUpTableHeader(builder, mutils.Header1Malformed, mutils.Header2Malformed, mutils.Header3Malformed)
+
+	for _, malformed := range rep.MalformedLines {
+		markUpTableRow(builder, malformed.File, mutils.GetMalformedKindName(malformed.Kind), strconv.Itoa(malformed.Count))
+	}
+}
+
+// markUpLatency размечает заголовок и таблицу времени обработки запросов, если оно известно,
+// записывая статистику всех запросов и первых highest запрашиваемых ресурсов.
+func markUpLatency(builder *strings.Builder, rep *report.Report, highest int) {
+	if rep.Latency.Count == 0 {
+		return
+	}
+
+	markUpTitle(builder, mutils.TitleLatency)
+	markUpTableHeader(builder, mutils.GetLatencyHeaders()...)
+	markUpTableRow(builder, mutils.GetLatencyRow(mutils.AllResources, &rep.Latency)...)
+
+	// Размечаются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.ResourceLatencies) && i < highest; i++ {
+		markUpTableRow(builder, mutils.GetLatencyRow(rep.ResourceLatencies[i].Resource, &rep.ResourceLatencies[i].Latency)...)
+	}
+}
+
+// markUpBuckets размечает заголовок и таблицу запросов по интервалам времени, если они заданы.
+func markUpBuckets(builder *strings.Builder, rep *report.Report) {
+	if len(rep.Buckets) == 0 {
+		return
+	}
+
+	withLatency := mutils.HasBucketLatency(rep)
+
+	markUpTitle(builder, mutils.TitleBuckets)
+	markUpTableHeader(builder, mutils.GetBucketHeaders(withLatency)...)
+
+	for i := range rep.Buckets {
+		markUpTableRow(builder, mutils.GetBucketRow(&rep.Buckets[i], withLatency)...)
+	}
+}
+
 // markUpResources размечает заголовок и таблицу заправшиваемых ресурсов.
 func markUpResources(builder *strings.Builder, rep *report.Report, highest int) {
 	markUpTitle(builder, mutils.TitleResources)
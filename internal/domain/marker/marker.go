@@ -2,7 +2,12 @@ package marker
 
 import (
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/adoc"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/csv"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/html"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/json"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/markdown"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/openmetrics"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/text"
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
 )
 
@@ -11,14 +16,47 @@ type marker interface {
 	MarkUp(rep *report.Report, highest int) (markup string)
 }
 
+// tableMarker описывает интерфейс разметчика, размечающего каждую таблицу отчёта в отдельный файл.
+type tableMarker interface {
+	MarkUpTables(rep *report.Report, highest int) (tables map[string][]byte, err error)
+}
+
+// Terminal описывает терминал, в который выводится отчёт формата text.
+type Terminal struct {
+	Color bool // Указывает, что терминал поддерживает цвета ANSI.
+	Width int  // Ширина терминала в символах или 0, если она неизвестна.
+}
+
 // New как фабрика возвращает конкретную реализацию marker в соответствии с markerType.
-func New(markerType string) marker {
+// Отчёт формата text размечается для вывода в терминал terminal.
+func New(markerType string, terminal Terminal) marker {
 	switch markerType {
 	case "markdown":
 		return &markdown.Marker{}
 	case "adoc":
 		return &adoc.Marker{}
+	case "html":
+		return &html.Marker{}
+	case "json":
+		return &json.Marker{}
+	case "openmetrics":
+		return &openmetrics.Marker{}
+	case "text":
+		return &text.Marker{Color: terminal.Color, Width: terminal.Width}
 	default:
 		return &markdown.Marker{}
 	}
 }
+
+// NewTables как фабрика возвращает конкретную реализацию tableMarker в соответствии с markerType.
+// Если отчёт формата markerType не размечается по таблицам, в качестве второго значения возвращает false.
+func NewTables(markerType string) (tableMarker, bool) {
+	switch markerType {
+	case "csv", "csv-zip":
+		return &csv.Marker{}, true
+	case "tsv", "tsv-zip":
+		return &csv.Marker{Comma: '\t'}, true
+	default:
+		return nil, false
+	}
+}
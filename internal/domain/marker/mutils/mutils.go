@@ -1,6 +1,12 @@
 package mutils
 
-import "strings"
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
 
 const (
 	TitleGeneralInfo   = "Общая информация"          // Заголовок.
@@ -8,16 +14,20 @@ const (
 	TitleCodes         = "Коды ответа"               // Заголовок.
 	TitleClients       = "IP-адреса клиентов"        // Заголовок.
 	TitleAgents        = "HTTP-заголовки User-Agent" // Заголовок.
+	TitleFormats       = "Форматы файлов"            // Заголовок.
+	TitleMalformed     = "Некорректные строки"       // Заголовок.
+	TitleLatency       = "Время обработки запросов"  // Заголовок.
+	TitleBuckets       = "Запросы по времени"        // Заголовок.
+	TitleReport        = "Отчёт анализа логов"       // Заголовок страницы отчёта.
 	Header1GeneralInfo = "Метрика"                   // Название 1-ого столбца таблицы общей информации.
 	Header2GeneralInfo = "Значение"                  // Название 2-ого столбца таблицы общей информации.
 	Row1GeneralInfo    = "Файл(-ы)"                  // Название содержимого 1-ой строки таблицы общей информации.
 	Row2GeneralInfo    = "Начальная дата"            // Название содержимого 2-ой строки таблицы общей информации.
 	Row3GeneralInfo    = "Конечная дата"             // Название содержимого 3-ей строки таблицы общей информации.
 	Row4GeneralInfo    = "Фильтр"                    // Название содержимого 4-ой строки таблицы общей информации.
-	Row5GeneralInfo    = "Значение фильтра"          // Название содержимого 5-ой строки таблицы общей информации.
-	Row6GeneralInfo    = "Количество запросов"       // Название содержимого 6-ой строки таблицы общей информации.
-	Row7GeneralInfo    = "Средний размер ответа"     // Название содержимого 7-ой строки таблицы общей информации.
-	Row8GeneralInfo    = "95p размера ответа"        // Название содержимого 8-ой строки таблицы общей информации.
+	Row5GeneralInfo    = "Количество запросов"       // Название содержимого 5-ой строки таблицы общей информации.
+	Row6GeneralInfo    = "Средний размер ответа"     // Название содержимого 6-ой строки таблицы общей информации.
+	Row7GeneralInfo    = "95p размера ответа"        // Название содержимого 7-ой строки таблицы общей информации.
 	Header1Resources   = "Ресурс"                    // Название 1-ого столбца таблицы запрашиваеиых ресурсов.
 	Header2Resources   = "Количество"                // Название 2-ого столбца таблицы запрашиваеиых ресурсов.
 	Header1Codes       = "Код"                       // Название 1-ого столбца таблицы кодов ответа.
@@ -27,6 +37,26 @@ const (
 	Header2Clients     = "Количество"                // Название 2-ого столбца таблицы ip-адресов клиентов.
 	Header1Agents      = "Агент"                     // Название 1-ого столбца таблицы HTTP-заголовков User-Agent.
 	Header2Agents      = "Количество"                // Название 2-ого столбца таблицы HTTP-заголовков User-Agent.
+	Header1Formats     = "Файл"                      // Название 1-ого столбца таблицы форматов файлов.
+	Header2Formats     = "Формат"                    // Название 2-ого столбца таблицы форматов файлов.
+	UnrecognizedFormat = "не распознан"              // Значение формата файла, который не удалось определить.
+	Header1Malformed   = "Файл"                      // Название 1-ого столбца таблицы некорректных строк.
+	Header2Malformed   = "Ошибка"                    // Название 2-ого столбца таблицы некорректных строк.
+	Header3Malformed   = "Количество"                // Название 3-ого столбца таблицы некорректных строк.
+	Header1Latency     = "Ресурс"                    // Название 1-ого столбца таблицы времени обработки запросов.
+	Header2Latency     = "Количество"                // Название 2-ого столбца таблицы времени обработки запросов.
+	Header3Latency     = "Мин., с"                   // Название 3-его столбца таблицы времени обработки запросов.
+	Header4Latency     = "Среднее, с"                // Название 4-ого столбца таблицы времени обработки запросов.
+	Header5Latency     = "Макс., с"                  // Название 5-ого столбца таблицы времени обработки запросов.
+	AllResources       = "Все ресурсы"               // Название строки таблицы времени обработки всех запросов.
+	Header1Buckets     = "Начало"                    // Название 1-ого столбца таблицы запросов по времени.
+	Header2Buckets     = "Запросы"                   // Название 2-ого столбца таблицы запросов по времени.
+	HeaderBytesBuckets = "Байты"                     // Название столбца размера ответов таблицы запросов по времени.
+	HeaderP95Buckets   = "p95, с"                    // Название столбца 95-ого перцентиля времени обработки таблицы запросов по времени.
+	NoValue            = "-"                         // Значение ячейки, для которой нет данных.
+	OtherCodes         = "Прочие"                    // Название части диаграммы кодов ответа, не вошедших в таблицу.
+	BucketTimeLayout   = "2006-01-02 15:04 Z07:00"   // Формат начала интервала времени.
+	LatencyPrec        = 3                           // Количество знаков после точки во времени обработки запросов.
 	FloatFormat        = 'f'                         // Параметр функции форматирования числа с плавающей точкой.
 	Prec               = -1                          // Параметр функции форматирования числа с плавающей точкой.
 	BitSize            = 64                          // Параметр функции форматирования числа с плавающей точкой.
@@ -44,3 +74,104 @@ func GetTableCellWithMultipleValues(cell []string, separator string) string {
 
 	return builder.String()
 }
+
+// GetMalformedKindName возвращает название вида ошибки разбора некорректной строки лога.
+func GetMalformedKindName(kind string) string {
+	switch kind {
+	case report.MalformedFormat:
+		return "Несоответствие формату лога"
+	case report.MalformedRequest:
+		return "Некорректный запрос"
+	case report.MalformedTime:
+		return "Некорректное время"
+	case report.MalformedStatus:
+		return "Некорректный код ответа"
+	case report.MalformedBodyBytesSent:
+		return "Некорректный размер ответа"
+	default:
+		return "Прочие ошибки"
+	}
+}
+
+// GetLatencyHeaders возвращает названия столбцов таблицы времени обработки запросов,
+// включая столбцы перцентилей report.LatencyPercentiles.
+func GetLatencyHeaders() []string {
+	headers := []string{Header1Latency, Header2Latency, Header3Latency, Header4Latency, Header5Latency}
+
+	for _, rank := range report.LatencyPercentiles {
+		headers = append(headers, "p"+strconv.FormatFloat(rank, FloatFormat, Prec, BitSize))
+	}
+
+	return headers
+}
+
+// GetLatencyRow возвращает ячейки строки таблицы времени обработки запросов к ресурсу name.
+func GetLatencyRow(name string, latency *report.Latency) []string {
+	row := []string{
+		name,
+		strconv.Itoa(latency.Count),
+		formatSeconds(latency.Min),
+		formatSeconds(latency.Average),
+		formatSeconds(latency.Max),
+	}
+
+	for _, percentile := range latency.Percentiles {
+		row = append(row, formatSeconds(percentile))
+	}
+
+	return row
+}
+
+// formatSeconds возвращает строковое представление времени в секундах с точностью LatencyPrec.
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, FloatFormat, LatencyPrec, BitSize)
+}
+
+// HasBucketLatency проверяет, известно ли время обработки запросов хотя бы в одном интервале времени отчёта.
+func HasBucketLatency(rep *report.Report) bool {
+	for i := range rep.Buckets {
+		if rep.Buckets[i].Latency.Count != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetBucketHeaders возвращает названия столбцов таблицы запросов по времени, включая столбцы классов кодов ответа
+// report.StatusClasses и, если withLatency, среднего, 95p и максимального времени обработки запросов.
+func GetBucketHeaders(withLatency bool) []string {
+	headers := append([]string{Header1Buckets, Header2Buckets}, report.StatusClasses...)
+	headers = append(headers, HeaderBytesBuckets)
+
+	if withLatency {
+		headers = append(headers, Header4Latency, HeaderP95Buckets, Header5Latency)
+	}
+
+	return headers
+}
+
+// GetBucketRow возвращает ячейки строки таблицы запросов по времени для интервала bucket.
+func GetBucketRow(bucket *report.Bucket, withLatency bool) []string {
+	row := []string{bucket.Start.Format(BucketTimeLayout), strconv.Itoa(bucket.RequestsCount)}
+
+	for _, count := range bucket.StatusClasses {
+		row = append(row, strconv.Itoa(count))
+	}
+
+	row = append(row, strconv.Itoa(bucket.BytesSent))
+
+	if !withLatency {
+		return row
+	}
+
+	if bucket.Latency.Count == 0 {
+		return append(row, NoValue, NoValue, NoValue)
+	}
+
+	return append(row,
+		formatSeconds(bucket.Latency.Average),
+		formatSeconds(bucket.Latency.Percentiles[slices.Index(report.LatencyPercentiles, 95)]),
+		formatSeconds(bucket.Latency.Max),
+	)
+}
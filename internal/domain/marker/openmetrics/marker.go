@@ -0,0 +1,182 @@
+package openmetrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+// Имена семейств метрик отчёта.
+const (
+	MetricRequests         = "nginx_requests"
+	MetricResponseBytes    = "nginx_response_bytes"
+	MetricResourceRequests = "nginx_resource_requests"
+	MetricRequestDuration  = "nginx_request_duration_seconds"
+	MetricMalformedLines   = "nginx_malformed_lines"
+)
+
+const (
+	responseSizePercentile = 95       // Ранг перцентиля размера ответа, хранящегося в отчёте.
+	percentilesPerQuantile = 100      // Количество перцентилей в единице квантиля.
+	quantilePrec           = 12       // Количество значащих цифр квантиля, скрывающее погрешность деления ранга.
+	counterSuffix          = "_total" // Суффикс имён значений счётчиков.
+)
+
+// labelValueReplacer экранирует значения меток: обратную косую черту, кавычку и перевод строки.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Marker умеет размечать отчёт в текстовом формате OpenMetrics, совместимом с текстовым форматом Prometheus 0.0.4,
+// который читает textfile collector node_exporter.
+type Marker struct{}
+
+// MarkUp размечает отчёт метриками OpenMetrics: количеством запросов по кодам ответа, сводкой размера ответов,
+// количеством запросов к первым highest запрашиваемым ресурсам, сводкой времени обработки запросов,
+// если оно известно, и количеством некорректных строк по видам ошибок, если такие строки были пропущены.
+// Количество меток ресурсов ограничено highest, чтобы число временных рядов не зависело от содержимого логов.
+func (p *Marker) MarkUp(rep *report.Report, highest int) string {
+	var builder strings.Builder
+
+	markUpRequests(&builder, rep)
+	markUpResponseBytes(&builder, rep)
+	markUpResourceRequests(&builder, rep, highest)
+	markUpRequestDuration(&builder, rep)
+	markUpMalformedLines(&builder, rep)
+
+	builder.WriteString("# EOF\n")
+
+	return builder.String()
+}
+
+// markUpRequests размечает счётчик запросов по кодам ответа.
+func markUpRequests(builder *strings.Builder, rep *report.Report) {
+	markUpFamily(builder, MetricRequests, "counter", "", "Number of analyzed requests by status code.")
+
+	for _, code := range rep.MostFrequentCodes {
+		markUpSample(builder, MetricRequests+counterSuffix, strconv.Itoa(code.Count), "status", strconv.Itoa(code.Data))
+	}
+}
+
+// markUpResponseBytes размечает сводку размера ответов с 95-ым перцентилем.
+func markUpResponseBytes(builder *strings.Builder, rep *report.Report) {
+	markUpFamily(builder, MetricResponseBytes, "summary", "bytes", "Size of the responses to the analyzed requests.")
+
+	sum := 0.0
+
+	// Средний размер ответа не определён, если запросов нет.
+	if rep.RequestsCount != 0 {
+		sum = rep.AverageResponseSize * float64(rep.RequestsCount)
+
+		markUpSample(builder, MetricResponseBytes, formatFloat(rep.Percentile95ResponseSize),
+			"quantile", formatQuantile(responseSizePercentile))
+	}
+
+	markUpSample(builder, MetricResponseBytes+"_sum", formatFloat(sum))
+	markUpSample(builder, MetricResponseBytes+"_count", strconv.Itoa(rep.RequestsCount))
+}
+
+// markUpResourceRequests размечает счётчик запросов к первым highest запрашиваемым ресурсам.
+func markUpResourceRequests(builder *strings.Builder, rep *report.Report, highest int) {
+	markUpFamily(builder, MetricResourceRequests, "counter", "", "Number of requests to the most requested resources.")
+
+	// Размечаются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.MostFrequentResources) && i < highest; i++ {
+		markUpSample(builder, MetricResourceRequests+counterSuffix, strconv.Itoa(rep.MostFrequentResources[i].Count),
+			"resource", rep.MostFrequentResources[i].Data)
+	}
+}
+
+// markUpRequestDuration размечает сводку времени обработки запросов, если оно известно.
+func markUpRequestDuration(builder *strings.Builder, rep *report.Report) {
+	if rep.Latency.Count == 0 {
+		return
+	}
+
+	markUpFamily(builder, MetricRequestDuration, "summary", "seconds", "Processing time of the analyzed requests.")
+
+	for i, value := range rep.Latency.Percentiles {
+		markUpSample(builder, MetricRequestDuration, formatFloat(value),
+			"quantile", formatQuantile(report.LatencyPercentiles[i]))
+	}
+
+	markUpSample(builder, MetricRequestDuration+"_sum", formatFloat(rep.Latency.Average*float64(rep.Latency.Count)))
+	markUpSample(builder, MetricRequestDuration+"_count", strconv.Itoa(rep.Latency.Count))
+}
+
+// markUpMalformedLines размечает счётчик некорректных строк по видам ошибок разбора, если такие строки были пропущены.
+// Количество строк суммируется по файлам, чтобы метки не зависели от их путей.
+func markUpMalformedLines(builder *strings.Builder, rep *report.Report) {
+	if len(rep.MalformedLines) == 0 {
+		return
+	}
+
+	markUpFamily(builder, MetricMalformedLines, "counter", "", "Number of log lines that could not be parsed by kind of error.")
+
+	counts := make(map[string]int)
+	kinds := []string{}
+
+	for _, malformed := range rep.MalformedLines {
+		if _, ok := counts[malformed.Kind]; !ok {
+			kinds = append(kinds, malformed.Kind)
+		}
+
+		counts[malformed.Kind] += malformed.Count
+	}
+
+	for _, kind := range kinds {
+		markUpSample(builder, MetricMalformedLines+counterSuffix, strconv.Itoa(counts[kind]), "kind", kind)
+	}
+}
+
+// markUpFamily размечает метаданные семейства метрик name типа typ с единицей измерения unit, если она задана.
+// Метаданные счётчика относятся к имени его значений с суффиксом _total: textfile collector читает текстовый
+// формат Prometheus 0.0.4, в котором тип относится только к значениям с тем же именем, а остальные значения
+// считаются нетипизированными. Строки # UNIT и # EOF в этом формате являются комментариями.
+func markUpFamily(builder *strings.Builder, name, typ, unit, help string) {
+	if typ == "counter" {
+		name += counterSuffix
+	}
+
+	fmt.Fprintf(builder, "# TYPE %s %s\n", name, typ)
+
+	if unit != "" {
+		fmt.Fprintf(builder, "# UNIT %s %s\n", name, unit)
+	}
+
+	fmt.Fprintf(builder, "# HELP %s %s\n", name, help)
+}
+
+// markUpSample размечает значение value метрики name с метками, заданными парами имени и значения labels.
+func markUpSample(builder *strings.Builder, name, value string, labels ...string) {
+	builder.WriteString(name)
+
+	if len(labels) != 0 {
+		builder.WriteString("{")
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i != 0 {
+				builder.WriteString(",")
+			}
+
+			fmt.Fprintf(builder, "%s=\"%s\"", labels[i], labelValueReplacer.Replace(labels[i+1]))
+		}
+
+		builder.WriteString("}")
+	}
+
+	builder.WriteString(" ")
+	builder.WriteString(value)
+	builder.WriteString("\n")
+}
+
+// formatQuantile возвращает значение метки quantile для перцентиля ранга rank.
+func formatQuantile(rank float64) string {
+	return strconv.FormatFloat(rank/percentilesPerQuantile, 'g', quantilePrec, mutils.BitSize)
+}
+
+// formatFloat возвращает строковое представление значения метрики.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, mutils.FloatFormat, mutils.Prec, mutils.BitSize)
+}
@@ -0,0 +1,134 @@
+package openmetrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/openmetrics"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkUp(t *testing.T) {
+	type args struct {
+		rep     report.Report
+		highest int
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "report with latency and malformed lines",
+			args: args{
+				rep: report.Report{
+					RequestsCount: 10,
+					MostFrequentResources: []report.DataWithCount[string]{
+						{Data: `/search?q="a\b"`, Count: 6}, {Data: "/a", Count: 3}, {Data: "/b", Count: 1},
+					},
+					MostFrequentCodes:        []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 404, Count: 3}},
+					AverageResponseSize:      1373.5,
+					Percentile95ResponseSize: 2048,
+					MalformedLines: []report.MalformedLines{
+						{File: "a.txt", Kind: report.MalformedTime, Count: 2},
+						{File: "a.txt", Kind: report.MalformedFormat, Count: 1},
+						{File: "b.txt", Kind: report.MalformedTime, Count: 3},
+					},
+					Latency: report.Latency{
+						Count: 4, Min: 0.1, Average: 0.25, Max: 0.5, Percentiles: []float64{0.2, 0.4, 0.5, 0.5, 0.5},
+					},
+				},
+				highest: 2,
+			},
+			want: `# TYPE nginx_requests_total counter
+# HELP nginx_requests_total Number of analyzed requests by status code.
+nginx_requests_total{status="200"} 7
+nginx_requests_total{status="404"} 3
+# TYPE nginx_response_bytes summary
+# UNIT nginx_response_bytes bytes
+# HELP nginx_response_bytes Size of the responses to the analyzed requests.
+nginx_response_bytes{quantile="0.95"} 2048
+nginx_response_bytes_sum 13735
+nginx_response_bytes_count 10
+# TYPE nginx_resource_requests_total counter
+# HELP nginx_resource_requests_total Number of requests to the most requested resources.
+nginx_resource_requests_total{resource="/search?q=\"a\\b\""} 6
+nginx_resource_requests_total{resource="/a"} 3
+# TYPE nginx_request_duration_seconds summary
+# UNIT nginx_request_duration_seconds seconds
+# HELP nginx_request_duration_seconds Processing time of the analyzed requests.
+nginx_request_duration_seconds{quantile="0.5"} 0.2
+nginx_request_duration_seconds{quantile="0.9"} 0.4
+nginx_request_duration_seconds{quantile="0.95"} 0.5
+nginx_request_duration_seconds{quantile="0.99"} 0.5
+nginx_request_duration_seconds{quantile="0.999"} 0.5
+nginx_request_duration_seconds_sum 1
+nginx_request_duration_seconds_count 4
+# TYPE nginx_malformed_lines_total counter
+# HELP nginx_malformed_lines_total Number of log lines that could not be parsed by kind of error.
+nginx_malformed_lines_total{kind="time"} 5
+nginx_malformed_lines_total{kind="format"} 1
+# EOF
+`,
+		},
+		{
+			name: "report without requests",
+			args: args{
+				rep:     report.New(nil, "-", "-", "-", 0, nil, nil, nil, nil, 0, 0),
+				highest: 3,
+			},
+			want: `# TYPE nginx_requests_total counter
+# HELP nginx_requests_total Number of analyzed requests by status code.
+# TYPE nginx_response_bytes summary
+# UNIT nginx_response_bytes bytes
+# HELP nginx_response_bytes Size of the responses to the analyzed requests.
+nginx_response_bytes_sum 0
+nginx_response_bytes_count 0
+# TYPE nginx_resource_requests_total counter
+# HELP nginx_resource_requests_total Number of requests to the most requested resources.
+# EOF
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&openmetrics.Marker{}).MarkUp(&tt.args.rep, tt.args.highest)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMarkUpPrometheusTypes(t *testing.T) {
+	rep := report.Report{
+		RequestsCount:         2,
+		MostFrequentResources: []report.DataWithCount[string]{{Data: "/a", Count: 2}},
+		MostFrequentCodes:     []report.DataWithCount[int]{{Data: 200, Count: 2}},
+		MalformedLines:        []report.MalformedLines{{File: "a.txt", Kind: report.MalformedTime, Count: 1}},
+		Latency:               report.Latency{Count: 2, Average: 0.1, Percentiles: []float64{0.1, 0.1, 0.1, 0.1, 0.1}},
+	}
+
+	// Как и в текстовом формате Prometheus 0.0.4, тип относится к значениям с тем же именем,
+	// а у сводок - также к значениям с суффиксами _sum и _count.
+	types := make(map[string]string)
+
+	for _, line := range strings.Split(strings.TrimSuffix((&openmetrics.Marker{}).MarkUp(&rep, 1), "\n"), "\n") {
+		if fields := strings.Fields(line); len(fields) == 4 && fields[1] == "TYPE" {
+			types[fields[2]] = fields[3]
+
+			continue
+		} else if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(strings.Fields(line)[0], "{")
+		family := strings.TrimSuffix(strings.TrimSuffix(name, "_sum"), "_count")
+
+		if _, ok := types[name]; !ok {
+			assert.Equal(t, "summary", types[family], name)
+		}
+	}
+}
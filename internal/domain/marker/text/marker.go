@@ -0,0 +1,407 @@
+package text
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/mutils"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+)
+
+const (
+	gap            = "  "         // Разделитель столбцов таблицы.
+	rule           = "─"          // Символ линии под заголовками столбцов.
+	minColumnWidth = 8            // Ширина, меньше которой столбцы не сужаются.
+	colorBold      = "\x1b[1m"    // Escape-последовательность ANSI полужирного текста.
+	colorReset     = "\x1b[0m"    // Escape-последовательность ANSI, сбрасывающая оформление.
+	noStatusColumn = -1           // Индекс столбца кодов ответа таблицы, в которой их нет.
+	statusClassSfx = "xx"         // Окончание названия класса кодов ответа, например 2xx.
+	statusDigits   = "0123456789" // Цифры кода ответа.
+)
+
+// statusColors - escape-последовательности ANSI цветов классов кодов ответа в порядке report.StatusClasses.
+var statusColors = []string{"\x1b[34m", "\x1b[32m", "\x1b[36m", "\x1b[33m", "\x1b[31m"}
+
+// Marker умеет размечать отчёт выровненными таблицами для вывода в терминал.
+type Marker struct {
+	Color bool // Указывает, что заголовки и коды ответа выделяются цветами ANSI.
+	Width int  // Ширина терминала, под которую сужаются таблицы, или 0, если она не ограничена.
+}
+
+// table - таблица отчёта с заголовком title.
+type table struct {
+	title        string
+	headers      []string
+	rows         [][]string
+	statusColumn int // Индекс столбца кодов ответа, выделяемых цветом их класса, или noStatusColumn.
+}
+
+// MarkUp размечает отчёт выровненными по ширине символов таблицами, записывая первые highest значений таблиц,
+// не содержащих общую информацию. Числовые столбцы выравниваются по правому краю.
+// Если таблица шире Width, её самые широкие столбцы сужаются, а не поместившиеся значения,
+// например длинные HTTP-заголовки User-Agent, усекаются многоточием.
+func (p *Marker) MarkUp(rep *report.Report, highest int) string {
+	var builder strings.Builder
+
+	tables := []*table{
+		generalInfo(rep),
+		formats(rep),
+		malformed(rep),
+		latency(rep, highest),
+		buckets(rep),
+		resources(rep, highest),
+		codes(rep, highest),
+		clients(rep, highest),
+		agents(rep, highest),
+	}
+
+	for _, t := range tables {
+		if t != nil {
+			p.markUpTable(&builder, t)
+		}
+	}
+
+	return builder.String()
+}
+
+// generalInfo возвращает таблицу общей информации, в которой каждый файл записывается в отдельной строке.
+func generalInfo(rep *report.Report) *table {
+	t := &table{
+		title:        mutils.TitleGeneralInfo,
+		headers:      []string{mutils.Header1GeneralInfo, mutils.Header2GeneralInfo},
+		statusColumn: noStatusColumn,
+	}
+
+	for i, file := range rep.Files {
+		name := ""
+
+		if i == 0 {
+			name = mutils.Row1GeneralInfo
+		}
+
+		t.rows = append(t.rows, []string{name, file})
+	}
+
+	t.rows = append(t.rows,
+		[]string{mutils.Row2GeneralInfo, rep.From},
+		[]string{mutils.Row3GeneralInfo, rep.To},
+		[]string{mutils.Row4GeneralInfo, rep.Filter},
+		[]string{mutils.Row5GeneralInfo, strconv.Itoa(rep.RequestsCount)},
+		[]string{mutils.Row6GeneralInfo, strconv.FormatFloat(rep.AverageResponseSize,
+			mutils.FloatFormat, mutils.Prec, mutils.BitSize)},
+		[]string{mutils.Row7GeneralInfo, strconv.FormatFloat(rep.Percentile95ResponseSize,
+			mutils.FloatFormat, mutils.Prec, mutils.BitSize)},
+	)
+
+	return t
+}
+
+// formats возвращает таблицу форматов файлов, если формат определялся для каждого файла.
+func formats(rep *report.Report) *table {
+	if len(rep.FileFormats) == 0 && len(rep.UnrecognizedFiles) == 0 {
+		return nil
+	}
+
+	t := &table{
+		title:        mutils.TitleFormats,
+		headers:      []string{mutils.Header1Formats, mutils.Header2Formats},
+		statusColumn: noStatusColumn,
+	}
+
+	for _, fileFormat := range rep.FileFormats {
+		t.rows = append(t.rows, []string{fileFormat.File, fileFormat.Format})
+	}
+
+	for _, file := range rep.UnrecognizedFiles {
+		t.rows = append(t.rows, []string{file, mutils.UnrecognizedFormat})
+	}
+
+	return t
+}
+
+// malformed возвращает таблицу некорректных строк, если такие строки были пропущены.
+func malformed(rep *report.Report) *table {
+	if len(rep.MalformedLines) == 0 {
+		return nil
+	}
+
+	t := &table{
+		title:        mutils.TitleMalformed,
+		headers:      []string{mutils.Header1Malformed, mutils.Header2Malformed, mutils.Header3Malformed},
+		statusColumn: noStatusColumn,
+	}
+
+	for _, m := range rep.MalformedLines {
+		t.rows = append(t.rows, []string{m.File, mutils.GetMalformedKindName(m.Kind), strconv.Itoa(m.Count)})
+	}
+
+	return t
+}
+
+// latency возвращает таблицу времени обработки запросов, если оно известно,
+// со статистикой всех запросов и первых highest запрашиваемых ресурсов.
+func latency(rep *report.Report, highest int) *table {
+	if rep.Latency.Count == 0 {
+		return nil
+	}
+
+	t := &table{
+		title:        mutils.TitleLatency,
+		headers:      mutils.GetLatencyHeaders(),
+		rows:         [][]string{mutils.GetLatencyRow(mutils.AllResources, &rep.Latency)},
+		statusColumn: noStatusColumn,
+	}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.ResourceLatencies) && i < highest; i++ {
+		t.rows = append(t.rows, mutils.GetLatencyRow(rep.ResourceLatencies[i].Resource, &rep.ResourceLatencies[i].Latency))
+	}
+
+	return t
+}
+
+// buckets возвращает таблицу запросов по интервалам времени, если они заданы.
+func buckets(rep *report.Report) *table {
+	if len(rep.Buckets) == 0 {
+		return nil
+	}
+
+	withLatency := mutils.HasBucketLatency(rep)
+
+	t := &table{
+		title:        mutils.TitleBuckets,
+		headers:      mutils.GetBucketHeaders(withLatency),
+		statusColumn: noStatusColumn,
+	}
+
+	for i := range rep.Buckets {
+		t.rows = append(t.rows, mutils.GetBucketRow(&rep.Buckets[i], withLatency))
+	}
+
+	return t
+}
+
+// resources возвращает таблицу первых highest запрашиваемых ресурсов.
+func resources(rep *report.Report, highest int) *table {
+	return counts(mutils.TitleResources, mutils.Header1Resources, mutils.Header2Resources, rep.MostFrequentResources, highest)
+}
+
+// codes возвращает таблицу первых highest кодов ответа, коды которой выделяются цветом их класса.
+func codes(rep *report.Report, highest int) *table {
+	t := &table{
+		title:        mutils.TitleCodes,
+		headers:      []string{mutils.Header1Codes, mutils.Header2Codes, mutils.Header3Codes},
+		statusColumn: 0,
+	}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(rep.MostFrequentCodes) && i < highest; i++ {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(rep.MostFrequentCodes[i].Data),
+			http.StatusText(rep.MostFrequentCodes[i].Data),
+			strconv.Itoa(rep.MostFrequentCodes[i].Count),
+		})
+	}
+
+	return t
+}
+
+// clients возвращает таблицу первых highest ip-адресов клиентов.
+func clients(rep *report.Report, highest int) *table {
+	return counts(mutils.TitleClients, mutils.Header1Clients, mutils.Header2Clients, rep.MostFrequentClients, highest)
+}
+
+// agents возвращает таблицу первых highest HTTP-заголовков User-Agent.
+func agents(rep *report.Report, highest int) *table {
+	return counts(mutils.TitleAgents, mutils.Header1Agents, mutils.Header2Agents, rep.MostFrequentAgents, highest)
+}
+
+// counts возвращает таблицу с заголовком title и столбцами header1 и header2 из первых highest значений values.
+func counts(title, header1, header2 string, values []report.DataWithCount[string], highest int) *table {
+	t := &table{
+		title:        title,
+		headers:      []string{header1, header2},
+		statusColumn: noStatusColumn,
+	}
+
+	// Записываются первые highest значений, или все, если highest больше их количества.
+	for i := 0; i < len(values) && i < highest; i++ {
+		t.rows = append(t.rows, []string{values[i].Data, strconv.Itoa(values[i].Count)})
+	}
+
+	return t
+}
+
+// markUpTable размечает заголовок и таблицу t, отделяя её пустой строкой от предыдущей.
+func (p *Marker) markUpTable(builder *strings.Builder, t *table) {
+	for _, row := range t.rows {
+		for i := range row {
+			row[i] = sanitize(row[i])
+		}
+	}
+
+	widths := columnWidths(t)
+	numeric := numericColumns(t)
+
+	p.fit(widths)
+
+	if builder.Len() != 0 {
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(p.paint(t.title, colorBold))
+	builder.WriteString("\n")
+
+	cells := make([]string, len(t.headers))
+
+	for i, header := range t.headers {
+		color := colorBold
+
+		if statusColor, ok := getStatusColor(header); ok {
+			color = statusColor
+		}
+
+		cells[i] = p.paint(align(truncate(header, widths[i]), widths[i], numeric[i]), color)
+	}
+
+	markUpLine(builder, cells)
+
+	for i, width := range widths {
+		cells[i] = strings.Repeat(rule, width)
+	}
+
+	markUpLine(builder, cells)
+
+	for _, row := range t.rows {
+		for i, cell := range row {
+			cells[i] = align(truncate(cell, widths[i]), widths[i], numeric[i])
+
+			if statusColor, ok := getStatusColor(cell); ok && i == t.statusColumn {
+				cells[i] = p.paint(cells[i], statusColor)
+			}
+		}
+
+		markUpLine(builder, cells)
+	}
+}
+
+// markUpLine размечает строку таблицы из выровненных ячеек cells без пробелов в конце.
+func markUpLine(builder *strings.Builder, cells []string) {
+	builder.WriteString(strings.TrimRight(strings.Join(cells, gap), " "))
+	builder.WriteString("\n")
+}
+
+// columnWidths возвращает ширину столбцов таблицы t: наибольшую ширину их заголовков и значений.
+func columnWidths(t *table) []int {
+	widths := make([]int, len(t.headers))
+
+	for i, header := range t.headers {
+		widths[i] = displayWidth(header)
+	}
+
+	for _, row := range t.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	return widths
+}
+
+// numericColumns проверяет для каждого столбца таблицы t, являются ли все его значения числами.
+func numericColumns(t *table) []bool {
+	numeric := make([]bool, len(t.headers))
+
+	for i := range numeric {
+		numeric[i] = len(t.rows) != 0
+
+		for _, row := range t.rows {
+			if _, err := strconv.ParseFloat(row[i], mutils.BitSize); err != nil && row[i] != mutils.NoValue {
+				numeric[i] = false
+
+				break
+			}
+		}
+	}
+
+	return numeric
+}
+
+// fit сужает самые широкие из столбцов шириной widths, пока таблица не поместится в Width
+// или все столбцы не сузятся до minColumnWidth.
+func (p *Marker) fit(widths []int) {
+	if p.Width <= 0 {
+		return
+	}
+
+	total := displayWidth(gap) * (len(widths) - 1)
+
+	for _, width := range widths {
+		total += width
+	}
+
+	for total > p.Width {
+		widest := 0
+
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+
+		shrink := min(total-p.Width, widths[widest]-minColumnWidth)
+		widths[widest] -= shrink
+		total -= shrink
+	}
+}
+
+// align дополняет значение cell пробелами до ширины width слева, если right, или справа.
+func align(cell string, width int, right bool) string {
+	padding := strings.Repeat(" ", max(0, width-displayWidth(cell)))
+
+	if right {
+		return padding + cell
+	}
+
+	return cell + padding
+}
+
+// paint выделяет значение s цветом color, если цвета включены. Пробелы выравнивания остаются без оформления,
+// чтобы не выделялись фоном в терминалах.
+func (p *Marker) paint(s, color string) string {
+	if !p.Color {
+		return s
+	}
+
+	trimmed := strings.TrimLeft(s, " ")
+	left := s[:len(s)-len(trimmed)]
+	value := strings.TrimRight(trimmed, " ")
+	right := trimmed[len(value):]
+
+	return left + color + value + colorReset + right
+}
+
+// getStatusColor возвращает цвет класса кода ответа или названия класса value, например 404 или 4xx.
+func getStatusColor(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+
+	if len(value) != len("200") || !strings.ContainsRune(statusDigits, rune(value[0])) {
+		return "", false
+	}
+
+	if value[1:] != statusClassSfx && strings.Trim(value[1:], statusDigits) != "" {
+		return "", false
+	}
+
+	class, ok := report.StatusClass(int(value[0]-'0') * 100)
+	if !ok {
+		return "", false
+	}
+
+	return statusColors[class], true
+}
@@ -0,0 +1,120 @@
+package text_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/marker/text"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkUp(t *testing.T) {
+	rep := report.Report{
+		Files:         []string{"logs/a.txt", "logs/b.txt"},
+		From:          "2024-11-07T16:07:00Z",
+		To:            "-",
+		Filter:        "-",
+		RequestsCount: 10,
+		MostFrequentResources: []report.DataWithCount[string]{
+			{Data: "/core.svg", Count: 6}, {Data: "/统一", Count: 4},
+		},
+		MostFrequentCodes:   []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 404, Count: 3}},
+		MostFrequentClients: []report.DataWithCount[string]{{Data: "70.27.134.194", Count: 10}},
+		MostFrequentAgents: []report.DataWithCount[string]{
+			{Data: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0", Count: 10},
+			{Data: "curl/8.5.0\x1b[2J", Count: 1},
+		},
+		AverageResponseSize:      1373.5,
+		Percentile95ResponseSize: 2048,
+	}
+
+	type args struct {
+		marker  text.Marker
+		highest int
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "without colors and width",
+			args: args{marker: text.Marker{}, highest: 2},
+			want: `Общая информация
+Метрика                Значение
+─────────────────────  ────────────────────
+Файл(-ы)               logs/a.txt
+                       logs/b.txt
+Начальная дата         2024-11-07T16:07:00Z
+Конечная дата          -
+Фильтр                 -
+Количество запросов    10
+Средний размер ответа  1373.5
+95p размера ответа     2048
+
+Запрашиваемые ресурсы
+Ресурс     Количество
+─────────  ──────────
+/core.svg           6
+/统一               4
+
+Коды ответа
+Код  Имя        Количество
+───  ─────────  ──────────
+200  OK                  7
+404  Not Found           3
+
+IP-адреса клиентов
+Клиент         Количество
+─────────────  ──────────
+70.27.134.194          10
+
+HTTP-заголовки User-Agent
+Агент                                                                                Количество
+───────────────────────────────────────────────────────────────────────────────────  ──────────
+Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0          10
+curl/8.5.0�[2J                                                                                1
+`,
+		},
+		{
+			name: "narrow terminal",
+			args: args{marker: text.Marker{Width: 40}, highest: 1},
+			want: `HTTP-заголовки User-Agent
+Агент                         Количество
+────────────────────────────  ──────────
+Mozilla/5.0 (X11; Linux x86…          10
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.args.marker.MarkUp(&rep, tt.args.highest)
+
+			if tt.args.marker.Width != 0 {
+				got = got[strings.Index(got, "HTTP-заголовки"):]
+
+				for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+					assert.LessOrEqual(t, len([]rune(line)), tt.args.marker.Width)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMarkUpColor(t *testing.T) {
+	rep := report.Report{
+		MostFrequentCodes: []report.DataWithCount[int]{{Data: 200, Count: 7}, {Data: 503, Count: 3}},
+	}
+
+	got := (&text.Marker{Color: true}).MarkUp(&rep, 3)
+
+	assert.Contains(t, got, "\x1b[1mКоды ответа\x1b[0m\n")
+	assert.Contains(t, got, "\x1b[32m200\x1b[0m  OK")
+	assert.Contains(t, got, "\x1b[31m503\x1b[0m  Service Unavailable")
+	assert.NotContains(t, (&text.Marker{}).MarkUp(&rep, 3), "\x1b[")
+}
@@ -0,0 +1,87 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+const ellipsis = "…" // Символ, которым заканчиваются усечённые значения.
+
+// wideRanges - диапазоны символов, занимающих в терминале две позиции: иероглифы, хангыль,
+// полноширинные формы и эмодзи.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x3FFFD},
+}
+
+// runeWidth возвращает количество позиций терминала, занимаемых символом r.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+
+	for _, wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return 2
+		}
+	}
+
+	return 1
+}
+
+// displayWidth возвращает количество позиций терминала, занимаемых строкой s.
+func displayWidth(s string) int {
+	width := 0
+
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+// truncate усекает строку s до width позиций терминала, заменяя её окончание многоточием.
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+
+	var builder strings.Builder
+
+	used := displayWidth(ellipsis)
+
+	for _, r := range s {
+		if used+runeWidth(r) > width {
+			break
+		}
+
+		used += runeWidth(r)
+
+		builder.WriteRune(r)
+	}
+
+	builder.WriteString(ellipsis)
+
+	return builder.String()
+}
+
+// sanitize заменяет управляющие символы значения s, например escape-последовательности из логов,
+// символом замены, чтобы они не управляли терминалом.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return unicode.ReplacementChar
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,20 @@
+package alb
+
+import (
+	"fmt"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// ErrNonALBLog - ошибка строки, не соответствующей формату лога AWS Application Load Balancer.
+type ErrNonALBLog struct {
+	data string
+}
+
+func (e ErrNonALBLog) Error() string {
+	return fmt.Sprintf("%s is not an AWS ALB log", e.data)
+}
+
+func (e ErrNonALBLog) Unwrap() error {
+	return parser.ErrMalformedFormat
+}
@@ -0,0 +1,201 @@
+package alb
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// Номера полей строки лога AWS Application Load Balancer.
+const (
+	fieldTime                   = 1
+	fieldClient                 = 3
+	fieldTarget                 = 4
+	fieldRequestProcessingTime  = 5
+	fieldTargetProcessingTime   = 6
+	fieldResponseProcessingTime = 7
+	fieldELBStatusCode          = 8
+	fieldSentBytes              = 11
+	fieldRequest                = 12
+	fieldUserAgent              = 13
+	fieldSSLProtocol            = 15
+	fieldTraceID                = 17
+	fieldDomainName             = 18
+	minFields                   = 19 // Минимальное количество полей строки, необходимое для её разбора.
+	requestParts                = 3  // Количество частей запроса: метод, URL и протокол.
+	missing                     = "-"
+	failedTime                  = "-1" // Время обработки, которым ALB обозначает незавершённую обработку.
+)
+
+// Parser умеет парсить строки лога AWS Application Load Balancer, поля которых разделены пробелами.
+type Parser struct{}
+
+// Parse парсит строку лога AWS ALB в log.Record.
+// Время обработки запроса целевым сервером, адрес целевого сервера и прочие расширенные поля записываются
+// в log.Record.Extra под именами соответствующих переменных nginx.
+func (p *Parser) Parse(lg string) (*log.Record, error) {
+	fields, ok := splitFields(lg)
+	if !ok || len(fields) < minFields {
+		return nil, fmt.Errorf("can`t split log into fields: %w", ErrNonALBLog{lg})
+	}
+
+	record := log.Record{
+		RemoteAddr:    splitHost(fields[fieldClient]),
+		RemoteUser:    missing,
+		HTTPRefer:     missing,
+		HTTPUserAgent: fields[fieldUserAgent],
+	}
+
+	timeLocal, err := parser.ParseTime(fields[fieldTime])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse time: %w", err)
+	}
+
+	record.TimeLocal = timeLocal
+
+	host, request, err := parseRequest(fields[fieldRequest])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse request: %w", err)
+	}
+
+	record.Request = request
+
+	record.Status, err = parser.ParseStatus(fields[fieldELBStatusCode])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse status: %w", err)
+	}
+
+	record.BodyBytesSent, err = parser.ParseBodyBytesSent(fields[fieldSentBytes])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse body bytes sent: %w", err)
+	}
+
+	if fields[fieldDomainName] != missing {
+		host = fields[fieldDomainName]
+	}
+
+	extra := map[string]string{
+		"host":                     host,
+		"upstream_addr":            fields[fieldTarget],
+		"request_processing_time":  fields[fieldRequestProcessingTime],
+		"upstream_response_time":   fields[fieldTargetProcessingTime],
+		"response_processing_time": fields[fieldResponseProcessingTime],
+		"ssl_protocol":             fields[fieldSSLProtocol],
+		"request_id":               fields[fieldTraceID],
+	}
+
+	if requestTime, ok := sumTimes(
+		fields[fieldRequestProcessingTime], fields[fieldTargetProcessingTime], fields[fieldResponseProcessingTime],
+	); ok {
+		extra["request_time"] = requestTime
+	}
+
+	for name, value := range extra {
+		if value != missing && value != "" && value != failedTime {
+			err = parser.FillRecord(&record, name, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &record, nil
+}
+
+// splitFields разбивает строку лога на поля, разделённые пробелами.
+// Поля, заключённые в двойные кавычки, могут содержать пробелы и экранированные кавычки.
+func splitFields(lg string) ([]string, bool) {
+	var (
+		fields  []string
+		builder strings.Builder
+	)
+
+	quoted, escaped, started := false, false, false
+
+	for _, r := range lg {
+		switch {
+		case escaped:
+			builder.WriteRune(r)
+
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				fields = append(fields, builder.String())
+			}
+
+			builder.Reset()
+
+			started = false
+		default:
+			builder.WriteRune(r)
+
+			started = true
+		}
+	}
+
+	if started {
+		fields = append(fields, builder.String())
+	}
+
+	return fields, !quoted
+}
+
+// splitHost возвращает адрес из пары адрес:порт.
+func splitHost(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
+// parseRequest парсит запрос ALB вида "GET http://host:80/path?query HTTP/1.1",
+// возвращая хост и log.Request, ресурс которого содержит путь с параметрами запроса.
+func parseRequest(request string) (string, log.Request, error) {
+	parts := strings.Fields(request)
+	if len(parts) != requestParts {
+		_, err := parser.ParseRequest(request)
+
+		return "", log.Request{}, fmt.Errorf("can`t split request: %w", err)
+	}
+
+	requestURL, err := url.Parse(parts[1])
+	if err != nil {
+		return "", log.Request{}, fmt.Errorf("can`t parse url: %w: %w", parser.ErrMalformedRequest, err)
+	}
+
+	parsed, err := parser.ParseRequest(parts[0] + " " + requestURL.RequestURI() + " " + parts[2])
+	if err != nil {
+		return "", log.Request{}, fmt.Errorf("can`t parse request: %w", err)
+	}
+
+	return requestURL.Hostname(), parsed, nil
+}
+
+// sumTimes возвращает сумму времён обработки запроса в секундах.
+// Если хотя бы одно из времён не определено, возвращает false.
+func sumTimes(times ...string) (string, bool) {
+	sum := 0.0
+
+	for _, t := range times {
+		seconds, err := strconv.ParseFloat(t, 64)
+		if err != nil || seconds < 0 {
+			return "", false
+		}
+
+		sum += seconds
+	}
+
+	return strconv.FormatFloat(sum, 'f', 3, 64), true
+}
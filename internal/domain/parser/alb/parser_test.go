@@ -0,0 +1,102 @@
+package alb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/alb"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	httpLog = `http 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 ` +
+		`0.000 0.001 0.000 200 200 34 366 "GET http://www.example.com:80/?a=1 HTTP/1.1" "curl/7.46.0" - - ` +
+		`arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 ` +
+		`"Root=1-58337262-36d228ad5d99923122bbe354" "-" "-" 0 2018-07-02T22:22:48.364000Z "forward" "-" "-" "10.0.0.1:80" "200" "-" "-"`
+	httpsLog = `https 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 - ` +
+		`0.001 -1 -1 502 - 34 366 "GET https://www.example.com:443/ HTTP/2.0" "Mozilla/5.0 (Windows NT 10.0)" ` +
+		`ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 - "Root=1-58337262-36d228ad5d99923122bbe354" "api.example.com" ` +
+		`"arn:aws:acm:us-east-2:123456789012:certificate/12345678" 1 2018-07-02T22:22:48.364000Z "forward" "-" "-" "-" "-" "-" "-"`
+	nginxLog = `93.180.71.3 - - [17/May/2015:08:05:32 +0000] "GET /downloads/product_1 HTTP/1.1" 304 0 "-" ` +
+		`"Debian APT-HTTP/1.3 (0.8.16~exp12ubuntu10.21)"`
+)
+
+func TestParse(t *testing.T) {
+	timeLocal, err := time.Parse(time.RFC3339, "2018-07-02T22:23:00.186641Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpRequestTime := 0.001
+
+	tests := []struct {
+		name    string
+		lg      string
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name: "http log",
+			lg:   httpLog,
+			want: &log.Record{
+				RemoteAddr:           "192.168.131.39",
+				RemoteUser:           "-",
+				TimeLocal:            timeLocal,
+				Request:              log.Request{Method: "GET", Resource: "/?a=1", Protocol: "HTTP/1.1"},
+				Status:               200,
+				BodyBytesSent:        366,
+				HTTPRefer:            "-",
+				HTTPUserAgent:        "curl/7.46.0",
+				RequestTime:          &httpRequestTime,
+				UpstreamResponseTime: []float64{0.001},
+				UpstreamAddr:         "10.0.0.1:80",
+				Host:                 "www.example.com",
+				RequestID:            "Root=1-58337262-36d228ad5d99923122bbe354",
+				Extra: map[string]string{
+					"request_processing_time":  "0.000",
+					"response_processing_time": "0.000",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "https log with failed target",
+			lg:   httpsLog,
+			want: &log.Record{
+				RemoteAddr:    "192.168.131.39",
+				RemoteUser:    "-",
+				TimeLocal:     timeLocal,
+				Request:       log.Request{Method: "GET", Resource: "/", Protocol: "HTTP/2.0"},
+				Status:        502,
+				BodyBytesSent: 366,
+				HTTPRefer:     "-",
+				HTTPUserAgent: "Mozilla/5.0 (Windows NT 10.0)",
+				Host:          "api.example.com",
+				RequestID:     "Root=1-58337262-36d228ad5d99923122bbe354",
+				SSLProtocol:   "TLSv1.2",
+				Extra: map[string]string{
+					"request_processing_time": "0.001",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "nginx log",
+			lg:      nginxLog,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &alb.Parser{}
+
+			got, err := ps.Parse(tt.lg)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package apache
+
+import (
+	"fmt"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// ErrNonApacheLog - ошибка строки, не соответствующей формату лога Apache httpd.
+type ErrNonApacheLog struct {
+	data string
+}
+
+func (e ErrNonApacheLog) Error() string {
+	return fmt.Sprintf("%s is not an Apache log", e.data)
+}
+
+func (e ErrNonApacheLog) Unwrap() error {
+	return parser.ErrMalformedFormat
+}
+
+// ErrInvalidLogFormat - ошибка директивы LogFormat, не содержащей ни одной директивы значения.
+type ErrInvalidLogFormat struct {
+	format string
+}
+
+func (e ErrInvalidLogFormat) Error() string {
+	return fmt.Sprintf("%s is not a valid log format", e.format)
+}
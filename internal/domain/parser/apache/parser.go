@@ -0,0 +1,207 @@
+package apache
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// Директивы LogFormat Apache httpd для стандартных форматов.
+const (
+	CommonFormat        = `%h %l %u %t "%r" %>s %b`
+	CombinedFormat      = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"`
+	VhostCombinedFormat = `%v:%p %h %l %u %t "%r" %>s %O "%{Referer}i" "%{User-Agent}i"`
+)
+
+// directiveRegExp находит директивы LogFormat вида %h, %>s или %{Referer}i.
+var directiveRegExp = regexp.MustCompile(`%(?:\{([^}]*)\})?[<>]?([a-zA-Z%])`)
+
+// directive описывает, как директива LogFormat сопоставляется переменной nginx.
+type directive struct {
+	variable string                    // Имя переменной nginx, в которую записывается значение директивы, если указано.
+	pattern  string                    // Регулярное выражение значения директивы.
+	convert  func(value string) string // Приведение значения директивы к значению переменной nginx.
+}
+
+// directives сопоставляет директивам LogFormat переменные nginx, в поля log.Record которых они записываются.
+var directives = map[string]directive{
+	"h": {variable: "remote_addr", pattern: `\S+`},
+	"a": {variable: "remote_addr", pattern: `\S+`},
+	"l": {pattern: `\S+`}, // Имя пользователя identd почти всегда не определено и не анализируется.
+	"u": {variable: "remote_user", pattern: `\S+`},
+	"t": {variable: "time_local", pattern: `\[[^\]]+\]`, convert: trimBrackets},
+	"r": {variable: "request", pattern: `.*?`},
+	"s": {variable: "status", pattern: `\d{3}`},
+	"b": {variable: "body_bytes_sent", pattern: `-|\d+`, convert: dashToZero},
+	"B": {variable: "body_bytes_sent", pattern: `\d+`},
+	"O": {variable: "body_bytes_sent", pattern: `-|\d+`, convert: dashToZero},
+	"I": {variable: "request_length", pattern: `-|\d+`, convert: dashToZero},
+	"D": {variable: "request_time", pattern: `\d+`, convert: microsecondsToSeconds},
+	"T": {variable: "request_time", pattern: `\d+`},
+	"v": {variable: "host", pattern: `\S+`},
+	"V": {variable: "host", pattern: `\S+`},
+	"p": {variable: "server_port", pattern: `\d+`},
+	"m": {variable: "request_method", pattern: `\S+`},
+	"U": {variable: "uri", pattern: `\S+`},
+	"q": {variable: "args", pattern: `\S*`},
+	"H": {variable: "server_protocol", pattern: `\S+`},
+}
+
+// Parser умеет парсить строки лога Apache httpd, записанного в соответствии с директивой LogFormat.
+// Нулевое значение Parser парсит строки формата combined.
+type Parser struct {
+	logRegExp  *regexp.Regexp   // Регулярное выражение, скомпилированное из директивы LogFormat.
+	directives []directive      // Директивы LogFormat в порядке групп захвата logRegExp.
+	times      parser.TimeCache // Последние распарсенные времена строк.
+}
+
+// combined - заранее скомпилированный Parser для формата combined, используемый нулевым значением Parser.
+var combined = mustNew(CombinedFormat)
+
+// New возвращает указатель на Parser, скомпилированный из директивы LogFormat Apache httpd.
+// Если директива не содержит длительности обработки запроса (%D или %T), Parser также распознаёт строки,
+// в конец которых через пробел записано значение %D.
+func New(logFormat string) (*Parser, error) {
+	indexes := directiveRegExp.FindAllStringSubmatchIndex(logFormat, -1)
+	if len(indexes) == 0 {
+		return nil, ErrInvalidLogFormat{logFormat}
+	}
+
+	var builder strings.Builder
+
+	parsed := make([]directive, 0, len(indexes)+1)
+	last := 0
+	hasDuration := false
+
+	builder.WriteString("^")
+
+	for _, index := range indexes {
+		builder.WriteString(regexp.QuoteMeta(logFormat[last:index[0]]))
+
+		last = index[1]
+		name := logFormat[index[4]:index[5]]
+
+		if name == "%" {
+			builder.WriteString("%")
+
+			continue
+		}
+
+		d := getDirective(name, logFormat, index)
+		hasDuration = hasDuration || d.variable == "request_time"
+
+		builder.WriteString("(" + d.pattern + ")")
+
+		parsed = append(parsed, d)
+	}
+
+	builder.WriteString(regexp.QuoteMeta(logFormat[last:]))
+
+	if !hasDuration { // Необязательная длительность обработки запроса в микросекундах.
+		builder.WriteString("(?: (" + directives["D"].pattern + "))?")
+
+		parsed = append(parsed, directives["D"])
+	}
+
+	builder.WriteString("$")
+
+	logRegExp, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, fmt.Errorf("can`t compile log format: %w", err)
+	}
+
+	return &Parser{
+		logRegExp:  logRegExp,
+		directives: parsed,
+	}, nil
+}
+
+// mustNew возвращает указатель на Parser, скомпилированный из logFormat, и паникует в случае ошибки.
+func mustNew(logFormat string) *Parser {
+	p, err := New(logFormat)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// getDirective возвращает описание директивы name, найденной в logFormat по индексам index.
+// Заголовки запроса %{Name}i сопоставляются переменным $http_name, неизвестные директивы записываются в Extra.
+func getDirective(name, logFormat string, index []int) directive {
+	if index[2] != -1 && name == "i" { // Заголовок запроса.
+		header := strings.ToLower(strings.ReplaceAll(logFormat[index[2]:index[3]], "-", "_"))
+
+		return directive{variable: "http_" + header, pattern: `.*?`}
+	}
+
+	if d, ok := directives[name]; ok && index[2] == -1 {
+		return d
+	}
+
+	return directive{variable: strings.Trim(logFormat[index[0]:index[1]], "%"), pattern: `.*?`}
+}
+
+// Parse парсит строку лога Apache httpd в log.Record.
+// Значения директив, не имеющих соответствующего поля в log.Record, записываются в log.Record.Extra.
+func (p *Parser) Parse(lg string) (*log.Record, error) {
+	times := &p.times
+
+	if p.logRegExp == nil {
+		p = combined
+	}
+
+	match := p.logRegExp.FindStringSubmatch(lg)
+	if match == nil {
+		return nil, fmt.Errorf("can`t find string submatch for log: %w", ErrNonApacheLog{lg})
+	}
+
+	record := log.Record{}
+
+	for i, d := range p.directives { // Парсинг групп захвата.
+		value := match[i+1]
+		if d.variable == "" || value == "" && d.variable == "request_time" { // Длительность может отсутствовать в строке.
+			continue
+		}
+
+		if d.convert != nil {
+			value = d.convert(value)
+		}
+
+		err := times.FillRecord(&record, d.variable, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &record, nil
+}
+
+// trimBrackets удаляет квадратные скобки, в которые заключено время записи.
+func trimBrackets(value string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+}
+
+// dashToZero приводит "-", которым Apache обозначает нулевой размер, к нулю.
+func dashToZero(value string) string {
+	if value == "-" {
+		return "0"
+	}
+
+	return value
+}
+
+// microsecondsToSeconds приводит длительность в микросекундах к секундам, как в переменной nginx $request_time.
+func microsecondsToSeconds(value string) string {
+	microseconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+
+	return strconv.FormatFloat((time.Duration(microseconds) * time.Microsecond).Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,127 @@
+package apache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/apache"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	layout      = "02/Jan/2006:15:04:05 -0700"
+	commonLog   = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+	combinedLog = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 304 - ` +
+		`"http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)" 1520`
+	vhostCombinedLog = `www.example.com:443 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2602 ` +
+		`"-" "curl/8.5.0"`
+)
+
+func TestParse(t *testing.T) {
+	firstTime, err := time.Parse(layout, "10/Oct/2000:13:55:36 -0700")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestTime := 0.00152
+
+	request := log.Request{
+		Method:   "GET",
+		Resource: "/apache_pb.gif",
+		Protocol: "HTTP/1.0",
+	}
+
+	type args struct {
+		logFormat string
+		lg        string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name: "common log",
+			args: args{
+				logFormat: apache.CommonFormat,
+				lg:        commonLog,
+			},
+			want: &log.Record{
+				RemoteAddr:    "127.0.0.1",
+				RemoteUser:    "frank",
+				TimeLocal:     firstTime,
+				Request:       request,
+				Status:        200,
+				BodyBytesSent: 2326,
+			},
+			wantErr: false,
+		},
+		{
+			name: "combined log with empty body and duration",
+			args: args{
+				logFormat: apache.CombinedFormat,
+				lg:        combinedLog,
+			},
+			want: &log.Record{
+				RemoteAddr:    "127.0.0.1",
+				RemoteUser:    "frank",
+				TimeLocal:     firstTime,
+				Request:       request,
+				Status:        304,
+				BodyBytesSent: 0,
+				HTTPRefer:     "http://www.example.com/start.html",
+				HTTPUserAgent: "Mozilla/4.08 [en] (Win98; I ;Nav)",
+				RequestTime:   &requestTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "vhost_combined log",
+			args: args{
+				logFormat: apache.VhostCombinedFormat,
+				lg:        vhostCombinedLog,
+			},
+			want: &log.Record{
+				RemoteAddr:    "127.0.0.1",
+				RemoteUser:    "-",
+				TimeLocal:     firstTime,
+				Request:       request,
+				Status:        200,
+				BodyBytesSent: 2602,
+				HTTPRefer:     "-",
+				HTTPUserAgent: "curl/8.5.0",
+				Host:          "www.example.com",
+				Extra: map[string]string{
+					"server_port": "443",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "common format with combined log",
+			args: args{
+				logFormat: apache.CommonFormat,
+				lg:        combinedLog,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := apache.New(tt.args.logFormat)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ps.Parse(tt.args.lg)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
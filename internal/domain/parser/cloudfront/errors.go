@@ -0,0 +1,20 @@
+package cloudfront
+
+import (
+	"fmt"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// ErrNonCloudFrontLog - ошибка строки, не соответствующей формату лога CloudFront.
+type ErrNonCloudFrontLog struct {
+	data string
+}
+
+func (e ErrNonCloudFrontLog) Error() string {
+	return fmt.Sprintf("%s is not a CloudFront log", e.data)
+}
+
+func (e ErrNonCloudFrontLog) Unwrap() error {
+	return parser.ErrMalformedFormat
+}
@@ -0,0 +1,147 @@
+package cloudfront
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+const (
+	fieldsPrefix   = "#Fields:" // Префикс строки заголовка, перечисляющей поля лога.
+	commentPrefix  = "#"        // Префикс служебных строк лога.
+	fieldSeparator = "\t"       // Разделитель полей строки лога.
+	missing        = "-"        // Значение отсутствующего поля.
+)
+
+// defaultFields - поля стандартного лога CloudFront, используемые до первой строки заголовка #Fields.
+var defaultFields = []string{
+	"date", "time", "x-edge-location", "sc-bytes", "c-ip", "cs-method", "cs(Host)", "cs-uri-stem", "sc-status",
+	"cs(Referer)", "cs(User-Agent)", "cs-uri-query", "cs(Cookie)", "x-edge-result-type", "x-edge-request-id",
+	"x-host-header", "cs-protocol", "cs-bytes", "time-taken", "x-forwarded-for", "ssl-protocol", "ssl-cipher",
+	"x-edge-response-result-type", "cs-protocol-version", "fle-status", "fle-encrypted-fields", "c-port",
+	"time-to-first-byte", "x-edge-detailed-result-type", "sc-content-type", "sc-content-len", "sc-range-start",
+	"sc-range-end",
+}
+
+// extraFields сопоставляет полям лога CloudFront переменные nginx, записываемые в log.Record.Extra.
+var extraFields = map[string]string{
+	"x-edge-location":    "edge_location",
+	"x-host-header":      "host",
+	"x-edge-request-id":  "request_id",
+	"time-taken":         "request_time",
+	"time-to-first-byte": "upstream_response_time",
+	"ssl-protocol":       "ssl_protocol",
+	"x-edge-result-type": "edge_result_type",
+}
+
+// Parser умеет парсить строки стандартного лога CloudFront в формате W3C, поля которых разделены табуляцией.
+// Порядок полей определяется последней прочитанной строкой заголовка #Fields.
+type Parser struct {
+	mu     sync.RWMutex
+	fields []string // Поля лога в порядке их следования в строке.
+}
+
+// Fork возвращает функцию парсинга строк лога CloudFront с порядком полей, независимым от Parser,
+// позволяющую одновременно парсить файлы с разными заголовками #Fields.
+func (p *Parser) Fork() func(lg string) (*log.Record, error) {
+	return (&Parser{}).Parse
+}
+
+// Parse парсит строку лога CloudFront в log.Record.
+// Для служебных строк, начинающихся с #, возвращает nil без ошибки, запоминая порядок полей из заголовка #Fields.
+func (p *Parser) Parse(lg string) (*log.Record, error) {
+	if strings.HasPrefix(lg, fieldsPrefix) {
+		p.mu.Lock()
+		p.fields = strings.Fields(strings.TrimPrefix(lg, fieldsPrefix))
+		p.mu.Unlock()
+
+		return nil, nil
+	} else if strings.HasPrefix(lg, commentPrefix) {
+		return nil, nil
+	}
+
+	p.mu.RLock()
+	names := p.fields
+	p.mu.RUnlock()
+
+	if names == nil {
+		names = defaultFields
+	}
+
+	values := strings.Split(lg, fieldSeparator)
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("can`t split log into fields: %w", ErrNonCloudFrontLog{lg})
+	}
+
+	fields := make(map[string]string, len(names))
+
+	for i, name := range names {
+		fields[name] = values[i]
+	}
+
+	return parseFields(lg, fields)
+}
+
+// parseFields формирует log.Record из полей строки лога lg.
+func parseFields(lg string, fields map[string]string) (*log.Record, error) {
+	timeLocal, err := parser.ParseTime(fields["date"] + "T" + fields["time"] + "Z") // Дата и время записаны в UTC.
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse time: %w", err)
+	}
+
+	record := log.Record{
+		RemoteAddr:    fields["c-ip"],
+		RemoteUser:    missing,
+		TimeLocal:     timeLocal,
+		HTTPRefer:     unescape(fields["cs(Referer)"]),
+		HTTPUserAgent: unescape(fields["cs(User-Agent)"]),
+		Request: log.Request{
+			Method:   fields["cs-method"],
+			Resource: fields["cs-uri-stem"],
+			Protocol: fields["cs-protocol-version"],
+		},
+	}
+
+	if record.Request.Method == "" || record.Request.Resource == "" {
+		return nil, fmt.Errorf("can`t find request: %w", ErrNonCloudFrontLog{lg})
+	}
+
+	if query := fields["cs-uri-query"]; query != missing && query != "" {
+		record.Request.Resource += "?" + query
+	}
+
+	record.Status, err = parser.ParseStatus(fields["sc-status"])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse status: %w", err)
+	}
+
+	record.BodyBytesSent, err = parser.ParseBodyBytesSent(fields["sc-bytes"])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse body bytes sent: %w", err)
+	}
+
+	for field, name := range extraFields {
+		if value, ok := fields[field]; ok && value != missing {
+			err = parser.FillRecord(&record, name, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &record, nil
+}
+
+// unescape декодирует значение поля, в котором CloudFront кодирует пробелы и спецсимволы как в URL.
+func unescape(value string) string {
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return value
+	}
+
+	return unescaped
+}
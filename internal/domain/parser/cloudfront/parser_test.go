@@ -0,0 +1,126 @@
+package cloudfront_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/cloudfront"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	header = "#Fields: date time x-edge-location sc-bytes c-ip cs-method cs(Host) cs-uri-stem sc-status cs(Referer) " +
+		"cs(User-Agent) cs-uri-query x-edge-request-id time-taken"
+	fieldsLog = strings.Join([]string{
+		"2019-12-04", "21:02:31", "LAX1", "392", "192.0.2.100", "GET", "d111111abcdef8.cloudfront.net", "/index.html",
+		"200", "-", "Mozilla/5.0%20(Windows%20NT%2010.0)", "a=1", "SOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==",
+		"0.082",
+	}, "\t")
+	defaultLog = strings.Join([]string{
+		"2019-12-04", "21:02:31", "LAX1", "392", "192.0.2.100", "GET", "d111111abcdef8.cloudfront.net", "/index.html",
+		"200", "-", "curl/7.68.0", "-", "-", "Hit", "SOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==",
+		"d111111abcdef8.cloudfront.net", "https", "23", "0.001", "-", "TLSv1.2", "ECDHE-RSA-AES128-GCM-SHA256", "Hit",
+		"HTTP/2.0", "-", "-", "11040", "0.001", "Hit", "text/html", "78", "-", "-",
+	}, "\t")
+)
+
+func TestParse(t *testing.T) {
+	timeLocal := time.Date(2019, time.December, 4, 21, 2, 31, 0, time.UTC)
+	fieldsRequestTime, defaultRequestTime := 0.082, 0.001
+
+	tests := []struct {
+		name    string
+		lines   []string
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name:  "log with fields header",
+			lines: []string{"#Version: 1.0", header, fieldsLog},
+			want: &log.Record{
+				RemoteAddr:    "192.0.2.100",
+				RemoteUser:    "-",
+				TimeLocal:     timeLocal,
+				Request:       log.Request{Method: "GET", Resource: "/index.html?a=1"},
+				Status:        200,
+				BodyBytesSent: 392,
+				HTTPRefer:     "-",
+				HTTPUserAgent: "Mozilla/5.0 (Windows NT 10.0)",
+				RequestTime:   &fieldsRequestTime,
+				RequestID:     "SOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==",
+				Extra: map[string]string{
+					"edge_location": "LAX1",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "log without fields header",
+			lines: []string{defaultLog},
+			want: &log.Record{
+				RemoteAddr:           "192.0.2.100",
+				RemoteUser:           "-",
+				TimeLocal:            timeLocal,
+				Request:              log.Request{Method: "GET", Resource: "/index.html", Protocol: "HTTP/2.0"},
+				Status:               200,
+				BodyBytesSent:        392,
+				HTTPRefer:            "-",
+				HTTPUserAgent:        "curl/7.68.0",
+				RequestTime:          &defaultRequestTime,
+				UpstreamResponseTime: []float64{0.001},
+				Host:                 "d111111abcdef8.cloudfront.net",
+				RequestID:            "SOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==",
+				SSLProtocol:          "TLSv1.2",
+				Extra: map[string]string{
+					"edge_location":    "LAX1",
+					"edge_result_type": "Hit",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "log not matching fields header",
+			lines:   []string{header, defaultLog},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &cloudfront.Parser{}
+
+			for _, line := range tt.lines[:len(tt.lines)-1] {
+				record, err := ps.Parse(line)
+				if err != nil || record != nil {
+					t.Fatalf("header %s is parsed as a record", line)
+				}
+			}
+
+			got, err := ps.Parse(tt.lines[len(tt.lines)-1])
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestFork(t *testing.T) {
+	ps := &cloudfront.Parser{}
+	parse := ps.Fork()
+
+	_, err := parse(header)
+	assert.NoError(t, err)
+
+	_, err = parse(fieldsLog)
+	assert.NoError(t, err)
+
+	// Заголовок, прочитанный функцией парсинга Fork, не меняет порядок полей Parser.
+	_, err = ps.Parse(fieldsLog)
+	assert.Error(t, err)
+
+	_, err = ps.Parse(defaultLog)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+)
+
+// combinedSeparators - разделители переменных формата combined, следующие за каждой из них, кроме последней.
+// Последняя переменная $http_user_agent завершается кавычкой в конце строки.
+var combinedSeparators = [...]string{" - ", " [", `] "`, `" `, " ", ` "`, `" "`}
+
+// parseCombined парсит строку лога формата combined без регулярного выражения, выделяя память только под log.Record.
+// Результат совпадает с результатом регулярного выражения формата: переменные выделяются так же, как ленивыми
+// группами захвата, а строки, для которых это требует перебора вариантов, парсятся регулярным выражением combined.
+func (p *Parser) parseCombined(lg string) (*log.Record, error) {
+	var values [len(combinedSeparators) + 1]string
+
+	// Точка (.) регулярного выражения не соответствует переводу строки, поэтому такие строки не являются записями.
+	if strings.IndexByte(lg, '\n') != -1 || !strings.HasSuffix(lg, `"`) {
+		return combined.parseRegExp(lg, &p.times)
+	}
+
+	rest := lg[:len(lg)-1]
+
+	for i, separator := range combinedSeparators {
+		end := strings.Index(rest, separator)
+		if end == -1 {
+			return combined.parseRegExp(lg, &p.times)
+		}
+
+		values[i], rest = rest[:end], rest[end+len(separator):]
+	}
+
+	values[len(combinedSeparators)] = rest
+
+	timeLocal, err := p.times.Parse(values[2])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse time: %w", err)
+	}
+
+	request, err := ParseRequest(values[3])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse request: %w", err)
+	}
+
+	status, err := ParseStatus(values[4])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse status: %w", err)
+	}
+
+	bodyBytesSent, err := ParseBodyBytesSent(values[5])
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse body bytes sent: %w", err)
+	}
+
+	return &log.Record{
+		RemoteAddr:    values[0],
+		RemoteUser:    values[1],
+		TimeLocal:     timeLocal,
+		Request:       request,
+		Status:        status,
+		BodyBytesSent: bodyBytesSent,
+		HTTPRefer:     values[6],
+		HTTPUserAgent: values[7],
+	}, nil
+}
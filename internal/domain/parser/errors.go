@@ -1,6 +1,19 @@
 package parser
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок разбора строки лога. Ошибки парсеров всех форматов разворачиваются в один из них,
+// поэтому вид ошибки определяется с помощью errors.Is независимо от формата лога.
+var (
+	ErrMalformedFormat        = errors.New("line does not match the log format")
+	ErrMalformedRequest       = errors.New("malformed request")
+	ErrMalformedTime          = errors.New("malformed time")
+	ErrMalformedStatus        = errors.New("malformed response status")
+	ErrMalformedBodyBytesSent = errors.New("malformed response body size")
+)
 
 // ErrNonNginxLog - ошибка строки, не соотвествующий формату строки nginx лога.
 type ErrNonNginxLog struct {
@@ -11,6 +24,10 @@ func (e ErrNonNginxLog) Error() string {
 	return fmt.Sprintf("%s is not an NGINX log", e.data)
 }
 
+func (e ErrNonNginxLog) Unwrap() error {
+	return ErrMalformedFormat
+}
+
 // ErrNonRequest - ошибка строки, не соотвествующий формату nginx http-запроса.
 type ErrNonRequest struct {
 	data string
@@ -19,3 +36,68 @@ type ErrNonRequest struct {
 func (e ErrNonRequest) Error() string {
 	return fmt.Sprintf("%s is not an http-request", e.data)
 }
+
+func (e ErrNonRequest) Unwrap() error {
+	return ErrMalformedRequest
+}
+
+// ErrInvalidLogFormat - ошибка директивы log_format, не содержащей ни одной переменной.
+type ErrInvalidLogFormat struct {
+	format string
+}
+
+func (e ErrInvalidLogFormat) Error() string {
+	return fmt.Sprintf("%s is not a valid log format", e.format)
+}
+
+// ErrWrongTime - ошибка строки, не соответствующей формату времени лога.
+type ErrWrongTime struct {
+	data string
+}
+
+func (e ErrWrongTime) Error() string {
+	return fmt.Sprintf("%s is not a log time", e.data)
+}
+
+func (e ErrWrongTime) Unwrap() error {
+	return ErrMalformedTime
+}
+
+// ErrWrongStatus - ошибка строки, не являющейся кодом ответа.
+type ErrWrongStatus struct {
+	data string
+}
+
+func (e ErrWrongStatus) Error() string {
+	return fmt.Sprintf("%s is not a response status", e.data)
+}
+
+func (e ErrWrongStatus) Unwrap() error {
+	return ErrMalformedStatus
+}
+
+// ErrWrongBodyBytesSent - ошибка строки, не являющейся размером ответа.
+type ErrWrongBodyBytesSent struct {
+	data string
+}
+
+func (e ErrWrongBodyBytesSent) Error() string {
+	return fmt.Sprintf("%s is not a response body size", e.data)
+}
+
+func (e ErrWrongBodyBytesSent) Unwrap() error {
+	return ErrMalformedBodyBytesSent
+}
+
+// ErrWrongDuration - ошибка строки, не являющейся длительностью в секундах.
+type ErrWrongDuration struct {
+	data string
+}
+
+func (e ErrWrongDuration) Error() string {
+	return fmt.Sprintf("%s is not a duration in seconds", e.data)
+}
+
+func (e ErrWrongDuration) Unwrap() error {
+	return ErrMalformedTime
+}
@@ -0,0 +1,15 @@
+package parser
+
+import "github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+
+// ParseWithRegExp парсит строку лога регулярным выражением директивы p, в том числе для формата combined.
+// Позволяет сравнивать быстрый парсер формата combined с регулярным выражением.
+func ParseWithRegExp(p *Parser, lg string) (*log.Record, error) {
+	times := &p.times
+
+	if p.logRegExp == nil {
+		p = combined
+	}
+
+	return p.parseRegExp(lg, times)
+}
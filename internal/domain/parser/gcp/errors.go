@@ -0,0 +1,20 @@
+package gcp
+
+import (
+	"fmt"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// ErrNonGCPLog - ошибка строки, не являющейся записью лога HTTP(S) Load Balancer Google Cloud.
+type ErrNonGCPLog struct {
+	data string
+}
+
+func (e ErrNonGCPLog) Error() string {
+	return fmt.Sprintf("%s is not a GCP HTTP load balancer log", e.data)
+}
+
+func (e ErrNonGCPLog) Unwrap() error {
+	return parser.ErrMalformedFormat
+}
@@ -0,0 +1,97 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/jsonl"
+)
+
+const missing = "-" // Значение отсутствующего поля.
+
+// mapping - сопоставление полей log.Record ключам записи Cloud Logging с полем httpRequest.
+const mapping = "remote_addr=httpRequest.remoteIp,time_local=timestamp,method=httpRequest.requestMethod," +
+	"resource=httpRequest.requestUrl,protocol=httpRequest.protocol,status=httpRequest.status," +
+	"body_bytes_sent=httpRequest.responseSize,http_referer=httpRequest.referer,http_user_agent=httpRequest.userAgent," +
+	"upstream_addr=httpRequest.serverIp,request_id=insertId"
+
+const (
+	latencyKey = "httpRequest.latency" // Ключ длительности обработки запроса вида "0.012345s".
+	cacheIDKey = "jsonPayload.cacheId" // Ключ идентификатора точки присутствия Cloud CDN, ответившей на запрос.
+)
+
+// httpRequest - JSON-парсер, сопоставляющий поля log.Record ключам записи Cloud Logging.
+var httpRequest = mustNew(mapping)
+
+// Parser умеет парсить записи лога HTTP(S) Load Balancer Google Cloud, выгруженные из Cloud Logging в виде JSON-строк.
+type Parser struct{}
+
+// Parse парсит запись лога HTTP(S) Load Balancer Google Cloud в log.Record.
+// Ключи записи, не сопоставленные полям log.Record, записываются в log.Record.Extra с путями через точку.
+func (p *Parser) Parse(lg string) (*log.Record, error) {
+	record, err := httpRequest.Parse(lg)
+	if err != nil {
+		if errors.As(err, &jsonl.ErrNonJSONLog{}) {
+			return nil, fmt.Errorf("can`t decode log: %w", ErrNonGCPLog{lg})
+		}
+
+		return nil, fmt.Errorf("can`t parse log: %w", err)
+	}
+
+	if record.Request.Method == "" || record.Request.Resource == "" {
+		return nil, fmt.Errorf("can`t find http request: %w", ErrNonGCPLog{lg})
+	}
+
+	requestURL, err := url.Parse(record.Request.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("can`t parse url: %w: %w", parser.ErrMalformedRequest, err)
+	}
+
+	record.Request.Resource = requestURL.RequestURI()
+	record.Host = requestURL.Hostname()
+
+	if latency, ok := record.Extra[latencyKey]; ok {
+		duration, err := time.ParseDuration(latency)
+		if err != nil {
+			return nil, fmt.Errorf("can`t parse latency: %w: %w", parser.ErrMalformedTime, err)
+		}
+
+		requestTime := duration.Seconds()
+		record.RequestTime = &requestTime
+
+		delete(record.Extra, latencyKey)
+	}
+
+	if cacheID, ok := record.Extra[cacheIDKey]; ok {
+		record.Extra["edge_location"] = cacheID
+
+		delete(record.Extra, cacheIDKey)
+	}
+
+	fillMissing(record)
+
+	return record, nil
+}
+
+// fillMissing записывает "-" в строковые поля, отсутствующие в записи Cloud Logging, как это делает nginx.
+func fillMissing(record *log.Record) {
+	for _, field := range []*string{&record.RemoteUser, &record.HTTPRefer, &record.HTTPUserAgent} {
+		if *field == "" {
+			*field = missing
+		}
+	}
+}
+
+// mustNew возвращает указатель на jsonl.Parser с сопоставлением полей m и паникует в случае ошибки.
+func mustNew(m string) *jsonl.Parser {
+	p, err := jsonl.New(m)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
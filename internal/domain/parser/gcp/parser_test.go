@@ -0,0 +1,81 @@
+package gcp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/gcp"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	gcpLog = `{"insertId":"1p5m2x8f3c9k0","jsonPayload":{"@type":"type.googleapis.com/google.cloud.loadbalancing.type.` +
+		`LoadBalancerLogEntry","statusDetails":"response_sent_by_backend"},"httpRequest":{"requestMethod":"POST",` +
+		`"requestUrl":"https://example.com/api/v1/items?limit=10","requestSize":"123","status":201,"responseSize":"456",` +
+		`"userAgent":"curl/8.5.0","remoteIp":"203.0.113.7","serverIp":"10.128.0.5","latency":"0.012345s",` +
+		`"protocol":"HTTP/1.1"},"resource":{"type":"http_load_balancer"},"timestamp":"2024-08-31T12:00:00.5Z",` +
+		`"severity":"INFO"}`
+	jsonLog = `{"remote_addr":"93.180.71.3","status":"304"}`
+)
+
+func TestParse(t *testing.T) {
+	requestTime := 0.012345
+
+	tests := []struct {
+		name    string
+		lg      string
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name: "http load balancer log",
+			lg:   gcpLog,
+			want: &log.Record{
+				RemoteAddr:    "203.0.113.7",
+				RemoteUser:    "-",
+				TimeLocal:     time.Date(2024, time.August, 31, 12, 0, 0, 500000000, time.UTC),
+				Request:       log.Request{Method: "POST", Resource: "/api/v1/items?limit=10", Protocol: "HTTP/1.1"},
+				Status:        201,
+				BodyBytesSent: 456,
+				HTTPRefer:     "-",
+				HTTPUserAgent: "curl/8.5.0",
+				RequestTime:   &requestTime,
+				UpstreamAddr:  "10.128.0.5",
+				Host:          "example.com",
+				RequestID:     "1p5m2x8f3c9k0",
+				Extra: map[string]string{
+					"httpRequest.requestSize":   "123",
+					"jsonPayload.@type":         "type.googleapis.com/google.cloud.loadbalancing.type.LoadBalancerLogEntry",
+					"jsonPayload.statusDetails": "response_sent_by_backend",
+					"resource.type":             "http_load_balancer",
+					"severity":                  "INFO",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "json log without http request",
+			lg:      jsonLog,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "non json log",
+			lg:      "not a json",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &gcp.Parser{}
+
+			got, err := ps.Parse(tt.lg)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
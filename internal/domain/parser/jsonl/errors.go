@@ -0,0 +1,38 @@
+package jsonl
+
+import (
+	"fmt"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+// ErrNonJSONLog - ошибка строки, не являющейся JSON-объектом.
+type ErrNonJSONLog struct {
+	data string
+}
+
+func (e ErrNonJSONLog) Error() string {
+	return fmt.Sprintf("%s is not a JSON log", e.data)
+}
+
+func (e ErrNonJSONLog) Unwrap() error {
+	return parser.ErrMalformedFormat
+}
+
+// ErrInvalidMapping - ошибка пары сопоставления, не соответствующей виду поле=ключ.
+type ErrInvalidMapping struct {
+	pair string
+}
+
+func (e ErrInvalidMapping) Error() string {
+	return fmt.Sprintf("%s is not a field=key pair", e.pair)
+}
+
+// ErrUnknownField - ошибка неизвестного поля log.Record в сопоставлении.
+type ErrUnknownField struct {
+	field string
+}
+
+func (e ErrUnknownField) Error() string {
+	return fmt.Sprintf("%s is not a known field", e.field)
+}
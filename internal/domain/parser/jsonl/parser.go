@@ -0,0 +1,324 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser"
+)
+
+const (
+	pathSeparator  = "." // Разделитель ключей пути к вложенному значению JSON-объекта.
+	pairSeparator  = "," // Разделитель пар сопоставления полей.
+	valueSeparator = "=" // Разделитель поля log.Record и пути к ключу JSON-объекта в паре сопоставления.
+)
+
+// defaultMapping - сопоставление полей log.Record ключам JSON-объекта по умолчанию.
+// Соответствует log_format с escape=json, ключи которого совпадают с именами переменных nginx.
+var defaultMapping = map[string]string{
+	"remote_addr":     "remote_addr",
+	"remote_user":     "remote_user",
+	"time_local":      "time_local",
+	"request":         "request",
+	"status":          "status",
+	"body_bytes_sent": "body_bytes_sent",
+	"http_referer":    "http_referer",
+	"http_user_agent": "http_user_agent",
+
+	"request_time":           "request_time",
+	"upstream_response_time": "upstream_response_time",
+	"upstream_addr":          "upstream_addr",
+	"host":                   "host",
+	"request_id":             "request_id",
+	"ssl_protocol":           "ssl_protocol",
+}
+
+// Parser умеет парсить строки лога, каждая из которых является JSON-объектом.
+// Нулевое значение Parser использует сопоставление полей по умолчанию.
+type Parser struct {
+	mapping map[string]string // Сопоставление полей log.Record путям к ключам JSON-объекта.
+}
+
+// New возвращает указатель на Parser с сопоставлением полей, заданным строкой mapping.
+// mapping имеет вид "status=response.status,remote_addr=client.ip", где слева указывается поле log.Record
+// (remote_addr, remote_user, time_local, request, method, resource, protocol, status, body_bytes_sent,
+// http_referer, http_user_agent, request_time, upstream_response_time, upstream_addr, host, request_id,
+// ssl_protocol), а справа - путь к ключу JSON-объекта, вложенные ключи разделяются точкой.
+// Поля, не указанные в mapping, сопоставляются ключам по умолчанию.
+func New(mapping string) (*Parser, error) {
+	p := &Parser{mapping: make(map[string]string, len(defaultMapping))}
+
+	for field, key := range defaultMapping {
+		p.mapping[field] = key
+	}
+
+	if mapping == "" {
+		return p, nil
+	}
+
+	for _, pair := range strings.Split(mapping, pairSeparator) {
+		field, key, ok := strings.Cut(strings.TrimSpace(pair), valueSeparator)
+		if !ok || key == "" {
+			return nil, ErrInvalidMapping{pair}
+		}
+
+		if !isKnownField(field) {
+			return nil, ErrUnknownField{field}
+		}
+
+		p.mapping[field] = key
+	}
+
+	return p, nil
+}
+
+// Parse парсит строку лога, являющуюся JSON-объектом, в log.Record.
+// Значения ключей, не сопоставленных полям log.Record, записываются в log.Record.Extra.
+func (p *Parser) Parse(lg string) (*log.Record, error) {
+	mapping := p.mapping
+	if mapping == nil {
+		mapping = defaultMapping
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(lg))
+	decoder.UseNumber()
+
+	object := make(map[string]any)
+
+	err := decoder.Decode(&object)
+	if err != nil {
+		return nil, fmt.Errorf("can`t decode log: %w", ErrNonJSONLog{lg})
+	}
+
+	// Строка должна состоять из одного JSON-объекта.
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("can`t decode log: %w", ErrNonJSONLog{lg})
+	}
+
+	// Объект без запроса, кода ответа и времени, например {} или null, не является записью лога.
+	if !hasRecordKey(object, mapping) {
+		return nil, fmt.Errorf("can`t decode log: %w", ErrNonJSONLog{lg})
+	}
+
+	record := log.Record{}
+	used := make(map[string]bool, len(mapping))
+
+	// Поля заполняются в фиксированном порядке, чтобы ошибка разбора была детерминированной.
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		value, ok := lookup(object, mapping[field])
+		if !ok {
+			continue
+		}
+
+		used[mapping[field]] = true
+
+		err = fillRecord(&record, field, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fillExtra(&record, object, "", used)
+
+	return &record, nil
+}
+
+// hasRecordKey проверяет, содержит ли object хотя бы один из ключей, сопоставленных запросу, его частям,
+// коду ответа или времени записи.
+func hasRecordKey(object map[string]any, mapping map[string]string) bool {
+	for _, field := range []string{"request", "method", "resource", "status", "time_local"} {
+		if _, ok := lookup(object, mapping[field]); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isKnownField проверяет, является ли field полем log.Record, доступным для сопоставления.
+func isKnownField(field string) bool {
+	switch field {
+	case "remote_addr", "remote_user", "time_local", "request", "method", "resource", "protocol",
+		"status", "body_bytes_sent", "http_referer", "http_user_agent", "request_time", "upstream_response_time",
+		"upstream_addr", "host", "request_id", "ssl_protocol":
+		return true
+	default:
+		return false
+	}
+}
+
+// lookup возвращает значение JSON-объекта по пути path к, возможно, вложенному ключу.
+// Ключ, совпадающий с path целиком, имеет приоритет над вложенным.
+func lookup(object map[string]any, path string) (any, bool) {
+	if value, ok := object[path]; ok {
+		return value, true
+	}
+
+	head, tail, ok := strings.Cut(path, pathSeparator)
+	if !ok {
+		return nil, false
+	}
+
+	nested, ok := object[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return lookup(nested, tail)
+}
+
+// fillRecord записывает значение value в поле field записи record, приводя его к типу поля.
+func fillRecord(record *log.Record, field string, value any) error {
+	switch field {
+	case "time_local":
+		timeLocal, err := toTime(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse time: %w", err)
+		}
+
+		record.TimeLocal = timeLocal
+	case "request":
+		request, err := parser.ParseRequest(toString(value))
+		if err != nil {
+			return fmt.Errorf("can`t parse request: %w", err)
+		}
+
+		record.Request = request
+	case "status":
+		status, err := toInt(value, parser.ParseStatus)
+		if err != nil {
+			return fmt.Errorf("can`t parse status: %w", err)
+		}
+
+		record.Status = status
+	case "body_bytes_sent":
+		bodyBytesSent, err := toInt(value, parser.ParseBodyBytesSent)
+		if err != nil {
+			return fmt.Errorf("can`t parse body bytes sent: %w", err)
+		}
+
+		record.BodyBytesSent = bodyBytesSent
+	case "request_time", "upstream_response_time", "upstream_addr", "host", "request_id", "ssl_protocol":
+		return parser.FillRecord(record, field, toString(value))
+	default:
+		fillStringField(record, field, toString(value))
+	}
+
+	return nil
+}
+
+// fillStringField записывает строковое значение value в поле field записи record.
+func fillStringField(record *log.Record, field, value string) {
+	switch field {
+	case "remote_addr":
+		record.RemoteAddr = value
+	case "remote_user":
+		record.RemoteUser = value
+	case "method":
+		record.Request.Method = value
+	case "resource":
+		record.Request.Resource = value
+	case "protocol":
+		record.Request.Protocol = value
+	case "http_referer":
+		record.HTTPRefer = value
+	case "http_user_agent":
+		record.HTTPUserAgent = value
+	}
+}
+
+// fillExtra записывает в record.Extra все значения object, пути к которым не содержатся в used.
+// Вложенные объекты разворачиваются, а пути к их ключам склеиваются через точку.
+func fillExtra(record *log.Record, object map[string]any, prefix string, used map[string]bool) {
+	for key, value := range object {
+		path := prefix + key
+
+		if used[path] {
+			continue
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			fillExtra(record, nested, path+pathSeparator, used)
+
+			continue
+		}
+
+		if record.Extra == nil {
+			record.Extra = make(map[string]string)
+		}
+
+		record.Extra[path] = toString(value)
+	}
+}
+
+// toString приводит значение JSON-объекта к строке.
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(v)
+
+		return string(data)
+	}
+}
+
+// toInt приводит целое число или строку с целым числом к int и проверяет его с помощью parse.
+// Пустая строка и "-", которыми nginx обозначает отсутствующие значения, приводятся к нулю.
+func toInt(value any, parse func(value string) (int, error)) (int, error) {
+	switch v := value.(type) {
+	case json.Number:
+		// Дробные числа и числа вне диапазона int64 не являются целыми и передаются parse как есть,
+		// чтобы он вернул свою ошибку.
+		number, err := v.Int64()
+		if err != nil {
+			return parse(v.String())
+		}
+
+		return parse(strconv.FormatInt(number, 10))
+	case string:
+		if v == "" || v == "-" {
+			return 0, nil
+		}
+
+		return parse(v)
+	default:
+		return parse(toString(value))
+	}
+}
+
+// toTime приводит время в формате nginx лога, RFC3339 или unix-время в секундах к time.Time.
+func toTime(value any) (time.Time, error) {
+	v, ok := value.(json.Number)
+	if !ok {
+		return parser.ParseTime(toString(value))
+	}
+
+	seconds, err := v.Float64()
+	if err != nil {
+		return parser.ParseTime(v.String())
+	}
+
+	integer, fraction := math.Modf(seconds)
+
+	return time.Unix(int64(integer), int64(fraction*float64(time.Second))).UTC(), nil
+}
@@ -0,0 +1,226 @@
+package jsonl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/parser/jsonl"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	layout  = "02/Jan/2006:15:04:05 -0700"
+	jsonLog = `{"remote_addr":"244.103.237.229","remote_user":"-","time_local":"17/Nov/2024:16:07:52 +0000",` +
+		`"request":"GET /reciprocal.hmtl HTTP/1.1","status":"200","body_bytes_sent":"2420",` +
+		`"http_referer":"-","http_user_agent":"curl/8.5.0","request_time":"0.120"}`
+	nestedJSONLog = `{"client":{"ip":"244.103.237.229"},"ts":"2024-11-17T16:07:52Z",` +
+		`"http":{"method":"GET","path":"/reciprocal.hmtl","proto":"HTTP/1.1"},` +
+		`"response":{"status":200,"bytes":2420},"ua":"curl/8.5.0"}`
+	nonJSONLog = `244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420 "-" "curl/8.5.0"`
+	mapping    = "remote_addr=client.ip,time_local=ts,method=http.method,resource=http.path,protocol=http.proto," +
+		"status=response.status,body_bytes_sent=response.bytes,http_user_agent=ua"
+)
+
+func TestParse(t *testing.T) {
+	firstTime, err := time.Parse(layout, "17/Nov/2024:16:07:52 +0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestTime := 0.12
+
+	type args struct {
+		mapping string
+		lg      string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name: "json log with default mapping",
+			args: args{
+				lg: jsonLog,
+			},
+			want: &log.Record{
+				RemoteAddr: "244.103.237.229",
+				RemoteUser: "-",
+				TimeLocal:  firstTime,
+				Request: log.Request{
+					Method:   "GET",
+					Resource: "/reciprocal.hmtl",
+					Protocol: "HTTP/1.1",
+				},
+				Status:        200,
+				BodyBytesSent: 2420,
+				HTTPRefer:     "-",
+				HTTPUserAgent: "curl/8.5.0",
+				RequestTime:   &requestTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "json log with nested keys and numbers",
+			args: args{
+				mapping: mapping,
+				lg:      nestedJSONLog,
+			},
+			want: &log.Record{
+				RemoteAddr: "244.103.237.229",
+				TimeLocal:  firstTime.UTC(),
+				Request: log.Request{
+					Method:   "GET",
+					Resource: "/reciprocal.hmtl",
+					Protocol: "HTTP/1.1",
+				},
+				Status:        200,
+				BodyBytesSent: 2420,
+				HTTPUserAgent: "curl/8.5.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "non-json log",
+			args: args{
+				lg: nonJSONLog,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "empty object",
+			args: args{
+				lg: `{}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "null",
+			args: args{
+				lg: `null`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "object without log keys",
+			args: args{
+				lg: `{"level":"info","msg":"started"}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "trailing garbage",
+			args: args{
+				lg: `{"status":200} junk`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "two objects",
+			args: args{
+				lg: `{"status":200}{"status":404}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "status out of range",
+			args: args{
+				lg: `{"status":99999}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "negative status",
+			args: args{
+				lg: `{"status":-5}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "fractional status",
+			args: args{
+				lg: `{"status":200.7}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "status overflowing int",
+			args: args{
+				lg: `{"status":1e30}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "negative body bytes sent",
+			args: args{
+				lg: `{"status":200,"body_bytes_sent":-1}`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := jsonl.New(tt.args.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ps.Parse(tt.args.lg)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		wantErr bool
+	}{
+		{
+			name:    "default mapping",
+			mapping: "",
+			wantErr: false,
+		},
+		{
+			name:    "custom mapping",
+			mapping: mapping,
+			wantErr: false,
+		},
+		{
+			name:    "unknown field",
+			mapping: "address=client.ip",
+			wantErr: true,
+		},
+		{
+			name:    "pair without key",
+			mapping: "remote_addr",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonl.New(tt.mapping)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
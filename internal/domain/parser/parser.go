@@ -2,90 +2,326 @@ package parser
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
 )
 
-const layout = "02/Jan/2006:15:04:05 -0700" // Формат времени nginx лога.
+const (
+	layout    = "02/Jan/2006:15:04:05 -0700" // Формат времени nginx лога.
+	missing   = "-"                          // Значение, которым nginx обозначает отсутствующую переменную.
+	maxStatus = 999                          // Наибольший трёхзначный код ответа.
+	// CombinedFormat - директива log_format nginx для формата combined, используемого по умолчанию.
+	CombinedFormat = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"`
+)
+
+// variableRegExp находит переменные директивы log_format вида $name или ${name}.
+var variableRegExp = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// upstreamSeparatorRegExp находит разделители значений переменных nginx $upstream_*.
+var upstreamSeparatorRegExp = regexp.MustCompile(`[,:]`)
+
+// combined - заранее скомпилированный Parser для формата combined, используемый нулевым значением Parser.
+var combined = mustNew(CombinedFormat)
+
+// Parser умеет парсить строки nginx лога, записанного в соответствии с директивой log_format.
+// Нулевое значение Parser парсит строки формата combined.
+// Строки формата combined парсятся без регулярного выражения.
+type Parser struct {
+	logRegExp  *regexp.Regexp // Регулярное выражение, скомпилированное из директивы log_format.
+	variables  []string       // Имена переменных директивы в порядке групп захвата logRegExp.
+	isCombined bool           // Указывает, что директива совпадает с CombinedFormat.
+	times      TimeCache      // Последние распарсенные времена строк.
+}
+
+// New возвращает указатель на Parser, скомпилированный из директивы log_format nginx.
+// Например, `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent $request_time`.
+func New(logFormat string) (*Parser, error) {
+	indexes := variableRegExp.FindAllStringSubmatchIndex(logFormat, -1)
+	if len(indexes) == 0 {
+		return nil, ErrInvalidLogFormat{logFormat}
+	}
+
+	var builder strings.Builder
+
+	variables := make([]string, 0, len(indexes))
+	last := 0
+
+	builder.WriteString("^")
 
-// Parser умеет парсить строки nginx лога.
-type Parser struct{}
+	for _, index := range indexes {
+		var name string
+
+		if index[2] != -1 { // Переменная записана в фигурных скобках.
+			name = logFormat[index[2]:index[3]]
+		} else {
+			name = logFormat[index[4]:index[5]]
+		}
+
+		builder.WriteString(regexp.QuoteMeta(logFormat[last:index[0]]))
+		builder.WriteString("(.*?)")
+
+		variables = append(variables, name)
+		last = index[1]
+	}
+
+	builder.WriteString(regexp.QuoteMeta(logFormat[last:]))
+	builder.WriteString("$")
+
+	logRegExp, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, fmt.Errorf("can`t compile log format: %w", err)
+	}
+
+	return &Parser{
+		logRegExp:  logRegExp,
+		variables:  variables,
+		isCombined: logFormat == CombinedFormat,
+	}, nil
+}
+
+// mustNew возвращает указатель на Parser, скомпилированный из logFormat, и паникует в случае ошибки.
+func mustNew(logFormat string) *Parser {
+	p, err := New(logFormat)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
 
 // Parse парсит строку nginx лога в log.Record.
+// Переменные директивы, не имеющие соответствующего поля в log.Record, записываются в log.Record.Extra.
 func (p *Parser) Parse(lg string) (*log.Record, error) {
-	logRegExp := regexp.MustCompile(
-		`(?P<RemoteAddr>.*) - (?P<RemoteUser>.*) ` +
-			`\[(?P<TimeLocal>.*)\] "(?P<Request>.*)" ` +
-			`(?P<Status>.*) (?P<BodyBytesSent>.*) ` +
-			`"(?P<HTTPRefer>.*)" "(?P<HTTPUserAgent>.*)"`,
-	)
-
-	match := logRegExp.FindStringSubmatch(lg)
-	if !logRegExp.MatchString(lg) || match == nil {
+	if p.logRegExp == nil || p.isCombined {
+		return p.parseCombined(lg)
+	}
+
+	return p.parseRegExp(lg, &p.times)
+}
+
+// parseRegExp парсит строку nginx лога в log.Record с помощью регулярного выражения директивы log_format.
+func (p *Parser) parseRegExp(lg string, times *TimeCache) (*log.Record, error) {
+	match := p.logRegExp.FindStringSubmatch(lg)
+	if match == nil {
 		return nil, fmt.Errorf("can`t find string submatch for log: %w", ErrNonNginxLog{lg})
 	}
 
-	result := make(map[string]string)
+	record := log.Record{}
 
-	for i, name := range logRegExp.SubexpNames() { // Парсинг групп захвата.
-		if i != 0 && name != "" {
-			result[name] = match[i]
+	for i, name := range p.variables { // Парсинг групп захвата.
+		err := times.FillRecord(&record, name, match[i+1])
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	record := log.Record{
-		RemoteAddr:    result["RemoteAddr"],
-		RemoteUser:    result["RemoteUser"],
-		HTTPRefer:     result["HTTPRefer"],
-		HTTPUserAgent: result["HTTPUserAgent"],
+	return &record, nil
+}
+
+// FillRecord записывает значение переменной nginx name в соответствующее поле record.
+// Значения переменных, не имеющих собственного поля, записываются в record.Extra.
+func FillRecord(record *log.Record, name, value string) error {
+	switch name {
+	case "remote_addr":
+		record.RemoteAddr = value
+	case "remote_user":
+		record.RemoteUser = value
+	case "time_local", "time_iso8601":
+		timeLocal, err := ParseTime(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse time: %w", err)
+		}
+
+		record.TimeLocal = timeLocal
+	case "request":
+		request, err := ParseRequest(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse request: %w", err)
+		}
+
+		record.Request = request
+	case "status":
+		status, err := ParseStatus(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse status: %w", err)
+		}
+
+		record.Status = status
+	case "body_bytes_sent":
+		bodyBytesSend, err := ParseBodyBytesSent(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse body bytes sent: %w", err)
+		}
+
+		record.BodyBytesSent = bodyBytesSend
+	case "http_referer":
+		record.HTTPRefer = value
+	case "http_user_agent":
+		record.HTTPUserAgent = value
+	case "request_time":
+		return fillRequestTime(record, value)
+	case "upstream_response_time":
+		upstreamResponseTime, err := ParseUpstreamTimes(value)
+		if err != nil {
+			return fmt.Errorf("can`t parse upstream response time: %w", err)
+		}
+
+		record.UpstreamResponseTime = upstreamResponseTime
+	case "upstream_addr":
+		record.UpstreamAddr = value
+	case "host":
+		record.Host = value
+	case "request_id":
+		record.RequestID = value
+	case "ssl_protocol":
+		record.SSLProtocol = value
+	default:
+		if record.Extra == nil {
+			record.Extra = make(map[string]string)
+		}
+
+		record.Extra[name] = value
 	}
 
-	request, err := parseRequest(result["Request"])
+	return nil
+}
+
+// fillRequestTime записывает в record время обработки запроса value, если оно известно.
+func fillRequestTime(record *log.Record, value string) error {
+	if value == missing {
+		return nil
+	}
+
+	requestTime, err := ParseSeconds(value)
 	if err != nil {
-		return nil, fmt.Errorf("can`t parse request: %w", err)
+		return fmt.Errorf("can`t parse request time: %w", err)
 	}
 
-	record.Request = request
+	record.RequestTime = &requestTime
 
-	timeLocal, err := time.Parse(layout, result["TimeLocal"])
-	if err != nil {
-		return nil, fmt.Errorf("can`t parse time: %w", err)
+	return nil
+}
+
+// ParseSeconds парсит длительность в секундах, например значение переменной nginx $request_time.
+// Отрицательные и нечисловые значения, в том числе NaN и Inf, не являются длительностью.
+func ParseSeconds(value string) (float64, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, ErrWrongDuration{value}
 	}
 
-	record.TimeLocal = timeLocal
+	return seconds, nil
+}
 
-	status, err := strconv.Atoi(result["Status"])
-	if err != nil {
-		return nil, fmt.Errorf("can`t parse status: %w", err)
+// ParseUpstreamTimes парсит значение переменной nginx $upstream_response_time, в котором времена ответа
+// нескольких upstream-серверов разделены запятыми, а групп серверов - двоеточиями.
+// Значения "-" серверов, ответ которых не был получен, пропускаются.
+func ParseUpstreamTimes(value string) ([]float64, error) {
+	var times []float64
+
+	for _, part := range upstreamSeparatorRegExp.Split(value, -1) {
+		if part = strings.TrimSpace(part); part == missing || part == "" {
+			continue
+		}
+
+		seconds, err := ParseSeconds(part)
+		if err != nil {
+			return nil, err
+		}
+
+		times = append(times, seconds)
 	}
 
-	record.Status = status
+	return times, nil
+}
+
+// ParseTime парсит время в формате nginx лога ($time_local) или в формате ISO 8601 ($time_iso8601).
+func ParseTime(value string) (time.Time, error) {
+	if timeLocal, err := time.Parse(layout, value); err == nil {
+		return timeLocal, nil
+	}
 
-	bodyBytesSend, err := strconv.Atoi(result["BodyBytesSent"])
+	timeLocal, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		return nil, fmt.Errorf("can`t parse body bytes sent: %w", err)
+		return time.Time{}, ErrWrongTime{value}
 	}
 
-	record.BodyBytesSent = bodyBytesSend
+	return timeLocal, nil
+}
 
-	return &record, nil
+// ParseStatus парсит код ответа, который должен быть целым числом от 0 до 999.
+func ParseStatus(value string) (int, error) {
+	status, err := strconv.Atoi(value)
+	if err != nil || status < 0 || status > maxStatus {
+		return 0, ErrWrongStatus{value}
+	}
+
+	return status, nil
 }
 
-// parseRequest парсит http-запрос в log.Request, разбивая его на строки метода, ресурса и протокола.
-func parseRequest(request string) (log.Request, error) {
-	reqRexEpx := regexp.MustCompile(`^(\w+)\s+(\S+)\s+(HTTP/\d\.\d)$`)
+// ParseBodyBytesSent парсит размер тела ответа, который должен быть неотрицательным целым числом.
+func ParseBodyBytesSent(value string) (int, error) {
+	bodyBytesSent, err := strconv.Atoi(value)
+	if err != nil || bodyBytesSent < 0 {
+		return 0, ErrWrongBodyBytesSent{value}
+	}
 
-	parts := reqRexEpx.FindStringSubmatch(request)
-	if len(parts) == 0 {
+	return bodyBytesSent, nil
+}
+
+// ParseRequest парсит http-запрос в log.Request, разбивая его на строки метода, ресурса и протокола.
+// Запрос должен соответствовать регулярному выражению `^(\w+)\s+(\S+)\s+(HTTP/\d\.\d)$`,
+// но разбирается побайтово, так как выполняется для каждой строки лога.
+func ParseRequest(request string) (log.Request, error) {
+	method := prefixLen(request, isWordChar)
+	methodSpace := method + prefixLen(request[method:], isSpace)
+	resource := methodSpace + prefixLen(request[methodSpace:], func(c byte) bool { return !isSpace(c) })
+	resourceSpace := resource + prefixLen(request[resource:], isSpace)
+	protocol := request[resourceSpace:]
+
+	if method == 0 || methodSpace == method || resource == methodSpace || resourceSpace == resource || !isProtocol(protocol) {
 		return log.Request{}, ErrNonRequest{request}
 	}
 
 	return log.Request{
-		Method:   parts[1],
-		Resource: parts[2],
-		Protocol: parts[3],
+		Method:   request[:method],
+		Resource: request[methodSpace:resource],
+		Protocol: protocol,
 	}, nil
 }
+
+// prefixLen возвращает длину наибольшего префикса value, все байты которого удовлетворяют match.
+func prefixLen(value string, match func(c byte) bool) int {
+	for i := range len(value) {
+		if !match(value[i]) {
+			return i
+		}
+	}
+
+	return len(value)
+}
+
+// isWordChar проверяет, соответствует ли байт классу \w регулярных выражений.
+func isWordChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || isDigit(c) || c == '_'
+}
+
+// isSpace проверяет, соответствует ли байт классу \s регулярных выражений.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+// isDigit проверяет, соответствует ли байт классу \d регулярных выражений.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isProtocol проверяет, соответствует ли value протоколу HTTP/\d\.\d.
+func isProtocol(value string) bool {
+	return len(value) == len("HTTP/1.1") && strings.HasPrefix(value, "HTTP/") && isDigit(value[5]) && value[6] == '.' && isDigit(value[7])
+}
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -65,3 +66,253 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWithLogFormat(t *testing.T) {
+	firstTime, err := time.Parse(layout, "17/Nov/2024:16:07:52 +0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestTime := 0.12
+
+	const logFormat = `$host $remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent ` +
+		`"$http_referer" "$http_user_agent" rt=${request_time} urt=$upstream_response_time cs=$upstream_cache_status`
+
+	tests := []struct {
+		name    string
+		lg      string
+		want    *log.Record
+		wantErr bool
+	}{
+		{
+			name: "log with extra variables",
+			lg: `example.com 244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420 ` +
+				`"-" "curl/8.5.0" rt=0.120 urt=0.118, 0.002 cs=MISS`,
+			want: &log.Record{
+				RemoteAddr: "244.103.237.229",
+				RemoteUser: "-",
+				TimeLocal:  firstTime,
+				Request: log.Request{
+					Method:   "GET",
+					Resource: "/reciprocal.hmtl",
+					Protocol: "HTTP/1.1",
+				},
+				Status:               200,
+				BodyBytesSent:        2420,
+				HTTPRefer:            "-",
+				HTTPUserAgent:        "curl/8.5.0",
+				RequestTime:          &requestTime,
+				UpstreamResponseTime: []float64{0.118, 0.002},
+				Host:                 "example.com",
+				Extra: map[string]string{
+					"upstream_cache_status": "MISS",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not a number request time",
+			lg: `example.com 244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420 ` +
+				`"-" "curl/8.5.0" rt=NaN urt=0.118 cs=MISS`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "infinite upstream response time",
+			lg: `example.com 244.103.237.229 - - [17/Nov/2024:16:07:52 +0000] "GET /reciprocal.hmtl HTTP/1.1" 200 2420 ` +
+				`"-" "curl/8.5.0" rt=0.120 urt=+Inf cs=MISS`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "combined log",
+			lg:      nginxLog,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	ps, err := parser.New(logFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ps.Parse(tt.lg)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestParseErrorKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		lg   string
+		want error
+	}{
+		{
+			name: "not a log",
+			lg:   "not a log",
+			want: parser.ErrMalformedFormat,
+		},
+		{
+			name: "wrong request",
+			lg:   `93.180.71.3 - - [17/May/2015:08:05:32 +0000] "GET" 304 0 "-" "curl/8.5.0"`,
+			want: parser.ErrMalformedRequest,
+		},
+		{
+			name: "wrong time",
+			lg:   `93.180.71.3 - - [17/May/2015] "GET /downloads/product_1 HTTP/1.1" 304 0 "-" "curl/8.5.0"`,
+			want: parser.ErrMalformedTime,
+		},
+		{
+			name: "wrong status",
+			lg:   `93.180.71.3 - - [17/May/2015:08:05:32 +0000] "GET /downloads/product_1 HTTP/1.1" 3040 0 "-" "curl/8.5.0"`,
+			want: parser.ErrMalformedStatus,
+		},
+		{
+			name: "wrong body bytes sent",
+			lg:   `93.180.71.3 - - [17/May/2015:08:05:32 +0000] "GET /downloads/product_1 HTTP/1.1" 304 -1 "-" "curl/8.5.0"`,
+			want: parser.ErrMalformedBodyBytesSent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&parser.Parser{}).Parse(tt.lg)
+
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestTimeCache(t *testing.T) {
+	var cache parser.TimeCache
+
+	// Времена чередуются, как в строках файлов, парсящихся одновременно.
+	for _, value := range []string{
+		"17/Nov/2024:16:07:52 +0000", "07/Nov/2024:20:59:55 +0300", "17/Nov/2024:16:07:52 +0000", "2024-11-07T20:59:55Z",
+	} {
+		want, err := parser.ParseTime(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := cache.Parse(value)
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := cache.Parse("17/Nov/2024")
+	assert.ErrorIs(t, err, parser.ErrMalformedTime)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		logFormat string
+		wantErr   bool
+	}{
+		{
+			name:      "combined format",
+			logFormat: parser.CombinedFormat,
+			wantErr:   false,
+		},
+		{
+			name:      "format without variables",
+			logFormat: "- - [] \"\"",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.New(tt.logFormat)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func FuzzParseCombined(f *testing.F) {
+	f.Add(nginxLog)
+	f.Add(nonNginxLog)
+	f.Add(`1.2.3.4 - - [17/Nov/2024:16:07:52 +0000] "GET / HTTP/1.1" 200 0 "-" "-"`)
+	f.Add(`1.2.3.4 - - - [17/Nov/2024:16:07:52 +0000] "GET / HTTP/1.1" 200 0 "a" "b" "c"`)
+	f.Add(`1.2.3.4 - - [17/Nov/2024:16:07:52 +0000] "GET /a b HTTP/1.1" 200 0 "-" "-"`)
+	f.Add(`1.2.3.4 - - [17/Nov/2024:16:07:52 +0000] "\x16\x03\x01" 400 150 "-" "-"`)
+	f.Add(`1.2.3.4 - - [2024-11-17T16:07:52Z] "GET / HTTP/1.1" 2O0 0 "-" "-"`)
+	f.Add("1.2.3.4 - - [17/Nov/2024:16:07:52 +0000] \"GET /\n HTTP/1.1\" 200 0 \"-\" \"-\"")
+
+	ps := &parser.Parser{}
+
+	f.Fuzz(func(t *testing.T, lg string) {
+		want, wantErr := parser.ParseWithRegExp(ps, lg)
+		got, err := ps.Parse(lg)
+
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantErr, err)
+	})
+}
+
+func FuzzParseRequest(f *testing.F) {
+	f.Add("GET /reciprocal.hmtl HTTP/1.1")
+	f.Add("POST\t/a?b=c  HTTP/2.0")
+	f.Add("GET / HTTP/1.1 ")
+	f.Add("\x16\x03\x01")
+	f.Add("GET /a b HTTP/1.1")
+
+	requestRegExp := regexp.MustCompile(`^(\w+)\s+(\S+)\s+(HTTP/\d\.\d)$`)
+
+	f.Fuzz(func(t *testing.T, request string) {
+		got, err := parser.ParseRequest(request)
+
+		if parts := requestRegExp.FindStringSubmatch(request); parts != nil {
+			assert.Equal(t, log.Request{Method: parts[1], Resource: parts[2], Protocol: parts[3]}, got)
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorAs(t, err, &parser.ErrNonRequest{})
+		}
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	combined, err := parser.New(parser.CombinedFormat)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarks := []struct {
+		name  string
+		parse func(lg string) (*log.Record, error)
+	}{
+		{
+			name:  "hand-written",
+			parse: combined.Parse,
+		},
+		{
+			name: "regexp",
+			parse: func(lg string) (*log.Record, error) {
+				return parser.ParseWithRegExp(combined, lg)
+			},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(nginxLog)))
+
+			for range b.N {
+				_, err := bm.parse(nginxLog)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
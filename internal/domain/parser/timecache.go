@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/log"
+)
+
+// timeCacheSlots - количество времён, одновременно хранящихся в TimeCache.
+const timeCacheSlots = 64
+
+// cachedTime хранит распарсенное время лога и его строку.
+type cachedTime struct {
+	value     string
+	timeLocal time.Time
+}
+
+// TimeCache хранит последние распарсенные времена лога, чтобы не парсить повторно совпадающие времена.
+// Строки лога обычно идут в порядке времени, и у соседних строк оно часто совпадает до секунды.
+// Каждое время хранится в ячейке, выбранной по его строке, поэтому файлы, одновременно парсящиеся
+// с разными временами, почти не вытесняют времена друг друга.
+// Нулевое значение TimeCache готово к использованию. TimeCache допускает одновременное использование.
+type TimeCache struct {
+	slots [timeCacheSlots]atomic.Pointer[cachedTime]
+}
+
+// Parse парсит время так же, как ParseTime, возвращая без парсинга время, совпадающее с сохранённым.
+// Nil TimeCache парсит каждое время.
+func (c *TimeCache) Parse(value string) (time.Time, error) {
+	if c == nil {
+		return ParseTime(value)
+	}
+
+	slot := &c.slots[hashString(value)%timeCacheSlots]
+
+	if cached := slot.Load(); cached != nil && cached.value == value {
+		return cached.timeLocal, nil
+	}
+
+	timeLocal, err := ParseTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Строка копируется, чтобы кэш не удерживал в памяти всю строку лога.
+	slot.Store(&cachedTime{value: strings.Clone(value), timeLocal: timeLocal})
+
+	return timeLocal, nil
+}
+
+// FillRecord записывает значение переменной nginx name в соответствующее поле record так же, как функция
+// FillRecord, но парсит время с помощью c.
+func (c *TimeCache) FillRecord(record *log.Record, name, value string) error {
+	if name != "time_local" && name != "time_iso8601" {
+		return FillRecord(record, name, value)
+	}
+
+	timeLocal, err := c.Parse(value)
+	if err != nil {
+		return fmt.Errorf("can`t parse time: %w", err)
+	}
+
+	record.TimeLocal = timeLocal
+
+	return nil
+}
+
+// hashString возвращает хэш FNV-1a строки value.
+func hashString(value string) uint32 {
+	const (
+		offset = 2166136261
+		prime  = 16777619
+	)
+
+	hash := uint32(offset)
+
+	for i := range len(value) {
+		hash ^= uint32(value[i])
+		hash *= prime
+	}
+
+	return hash
+}
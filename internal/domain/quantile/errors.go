@@ -0,0 +1,19 @@
+package quantile
+
+import "fmt"
+
+// ErrEmptySketch - ошибка оценки квантиля по Sketch, не содержащему значений.
+type ErrEmptySketch struct{}
+
+func (e ErrEmptySketch) Error() string {
+	return "sketch is empty"
+}
+
+// ErrWrongPercent - ошибка ранга перцентиля, не лежащего в (0, 100].
+type ErrWrongPercent struct {
+	data float64
+}
+
+func (e ErrWrongPercent) Error() string {
+	return fmt.Sprintf("%g is not a percent in (0, 100]", e.data)
+}
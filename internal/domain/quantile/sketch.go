@@ -0,0 +1,240 @@
+package quantile
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/montanaflynn/stats"
+)
+
+const (
+	DefaultAccuracy = 0.01 // Относительная погрешность оценки перцентилей по умолчанию.
+	DefaultCapacity = 1024 // Количество значений, хранимых точно до перехода к корзинам, по умолчанию.
+	minIndexable    = 1e-9 // Значения меньше minIndexable учитываются в корзине нулевых значений.
+)
+
+// Sketch оценивает перцентили потока неотрицательных значений в ограниченной памяти.
+// Пока количество значений не превышает capacity, они хранятся точно и перцентили совпадают со stats.Percentile.
+// Затем значения раскладываются по корзинам с экспоненциально растущими границами, как в HDR-гистограмме,
+// и относительная погрешность оценки перцентиля не превышает accuracy,
+// а память растёт лишь логарифмически от отношения максимального значения к минимальному.
+type Sketch struct {
+	exact    bool        // Указывает необходимость хранить все значения точно.
+	capacity int         // Количество значений, хранимых точно до перехода к корзинам.
+	gamma    float64     // Отношение верхней границы корзины к нижней.
+	logGamma float64     // Натуральный логарифм gamma.
+	values   []float64   // Точно хранимые значения, пока корзины не используются.
+	buckets  map[int]int // Количество значений по номерам корзин, если корзины используются.
+	zeros    int         // Количество значений в корзине нулевых значений.
+	count    int
+	sum      float64
+	min      float64
+	max      float64
+}
+
+// New возвращает указатель на Sketch, хранящий точно первые capacity значений
+// и оценивающий перцентили с относительной погрешностью accuracy после их превышения.
+// accuracy вне (0, 1) заменяется на DefaultAccuracy.
+func New(accuracy float64, capacity int) *Sketch {
+	if accuracy <= 0 || accuracy >= 1 {
+		accuracy = DefaultAccuracy
+	}
+
+	gamma := (1 + accuracy) / (1 - accuracy)
+
+	return &Sketch{
+		capacity: capacity,
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+	}
+}
+
+// NewExact возвращает указатель на Sketch, хранящий все значения и вычисляющий перцентили точно.
+// Память такого Sketch растёт линейно от количества значений, поэтому он подходит лишь для небольших логов.
+func NewExact() *Sketch {
+	return &Sketch{exact: true}
+}
+
+// Add добавляет значение value в Sketch. Отрицательные значения учитываются как нулевые.
+func (s *Sketch) Add(value float64) {
+	if s.count == 0 || value < s.min {
+		s.min = value
+	}
+
+	if s.count == 0 || value > s.max {
+		s.max = value
+	}
+
+	s.count++
+	s.sum += value
+
+	if s.buckets != nil {
+		s.addToBucket(value)
+
+		return
+	}
+
+	s.values = append(s.values, value)
+
+	if !s.exact && len(s.values) > s.capacity {
+		s.toBuckets()
+	}
+}
+
+// Merge добавляет в Sketch все значения other.
+// Результат не зависит от того, были ли значения добавлены в Sketch непосредственно или через Merge,
+// кроме суммы значений, погрешность округления которой зависит от порядка сложения.
+func (s *Sketch) Merge(other *Sketch) {
+	if other.count == 0 {
+		return
+	}
+
+	if s.count == 0 || other.min < s.min {
+		s.min = other.min
+	}
+
+	if s.count == 0 || other.max > s.max {
+		s.max = other.max
+	}
+
+	s.count += other.count
+	s.sum += other.sum
+
+	if s.buckets == nil && other.buckets == nil {
+		s.values = append(s.values, other.values...)
+
+		if !s.exact && len(s.values) > s.capacity {
+			s.toBuckets()
+		}
+
+		return
+	}
+
+	if s.buckets == nil {
+		s.toBuckets()
+	}
+
+	for _, value := range other.values {
+		s.addToBucket(value)
+	}
+
+	for index, count := range other.buckets {
+		s.buckets[index] += count
+	}
+
+	s.zeros += other.zeros
+}
+
+// toBuckets раскладывает точно хранимые значения по корзинам.
+func (s *Sketch) toBuckets() {
+	s.buckets = make(map[int]int)
+
+	for _, value := range s.values {
+		s.addToBucket(value)
+	}
+
+	s.values = nil
+}
+
+// addToBucket учитывает значение value в соответствующей ему корзине.
+func (s *Sketch) addToBucket(value float64) {
+	if value < minIndexable {
+		s.zeros++
+
+		return
+	}
+
+	s.buckets[int(math.Ceil(math.Log(value)/s.logGamma))]++
+}
+
+// Count возвращает количество значений, добавленных в Sketch.
+func (s *Sketch) Count() int {
+	return s.count
+}
+
+// Sum возвращает сумму значений, добавленных в Sketch.
+func (s *Sketch) Sum() float64 {
+	return s.sum
+}
+
+// Min возвращает минимальное значение, добавленное в Sketch.
+func (s *Sketch) Min() float64 {
+	return s.min
+}
+
+// Max возвращает максимальное значение, добавленное в Sketch.
+func (s *Sketch) Max() float64 {
+	return s.max
+}
+
+// Mean возвращает среднее значений, добавленных в Sketch.
+func (s *Sketch) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+
+	return s.sum / float64(s.count)
+}
+
+// Percentile возвращает перцентиль percent значений, добавленных в Sketch.
+// Ранги значений выбираются так же, как в stats.Percentile, поэтому для точно хранимых значений результаты совпадают.
+func (s *Sketch) Percentile(percent float64) (float64, error) {
+	if s.count == 0 {
+		return 0, ErrEmptySketch{}
+	}
+
+	if s.buckets == nil {
+		percentile, err := stats.Percentile(s.values, percent)
+		if err != nil {
+			return 0, fmt.Errorf("can`t calculate percentile: %w", err)
+		}
+
+		return percentile, nil
+	}
+
+	if percent <= 0 || percent > 100 {
+		return 0, ErrWrongPercent{percent}
+	}
+
+	index := percent / 100 * float64(s.count)
+
+	switch rank := int(index); {
+	case index == float64(rank):
+		return s.valueAt(rank), nil
+	case index > 1:
+		return (s.valueAt(rank) + s.valueAt(rank+1)) / 2, nil
+	default:
+		return 0, ErrWrongPercent{percent}
+	}
+}
+
+// valueAt возвращает оценку значения с рангом rank, начиная с 1, в порядке возрастания значений.
+func (s *Sketch) valueAt(rank int) float64 {
+	if rank <= s.zeros {
+		return max(s.min, 0)
+	}
+
+	indexes := make([]int, 0, len(s.buckets))
+
+	for index := range s.buckets {
+		indexes = append(indexes, index)
+	}
+
+	sort.Ints(indexes)
+
+	seen := s.zeros
+
+	for _, index := range indexes {
+		seen += s.buckets[index]
+
+		if rank <= seen {
+			// Середина корзины (gamma^(index-1), gamma^index], равноудалённая от её границ в относительном смысле.
+			value := 2 * math.Exp(float64(index)*s.logGamma) / (s.gamma + 1)
+
+			return min(max(value, s.min), s.max)
+		}
+	}
+
+	return s.max
+}
@@ -0,0 +1,150 @@
+package quantile_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/quantile"
+	"github.com/montanaflynn/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketchPercentile(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	values := make([]float64, 10000)
+	for i := range values {
+		values[i] = math.Exp(rnd.NormFloat64()*2) * 1000
+	}
+
+	values[0] = 0
+
+	tests := []struct {
+		name     string
+		sketch   *quantile.Sketch
+		accuracy float64
+	}{
+		{
+			name:     "exact",
+			sketch:   quantile.NewExact(),
+			accuracy: 0,
+		},
+		{
+			name:     "under capacity",
+			sketch:   quantile.New(0.01, len(values)),
+			accuracy: 0,
+		},
+		{
+			name:     "default accuracy",
+			sketch:   quantile.New(quantile.DefaultAccuracy, quantile.DefaultCapacity),
+			accuracy: quantile.DefaultAccuracy,
+		},
+		{
+			name:     "low accuracy",
+			sketch:   quantile.New(0.05, 0),
+			accuracy: 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, value := range values {
+				tt.sketch.Add(value)
+			}
+
+			for _, percent := range []float64{1, 50, 90, 95, 99, 99.9, 100} {
+				want, err := stats.Percentile(values, percent)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := tt.sketch.Percentile(percent)
+
+				assert.NoError(t, err)
+				assert.InDelta(t, want, got, want*tt.accuracy+1e-9, "percentile %g", percent)
+			}
+
+			assert.Equal(t, len(values), tt.sketch.Count())
+			assert.Equal(t, 0.0, tt.sketch.Min())
+		})
+	}
+}
+
+func TestSketchErrors(t *testing.T) {
+	sketch := quantile.New(quantile.DefaultAccuracy, 0)
+
+	_, err := sketch.Percentile(50)
+	assert.ErrorAs(t, err, &quantile.ErrEmptySketch{})
+
+	sketch.Add(1)
+	sketch.Add(2)
+
+	_, err = sketch.Percentile(101)
+	assert.ErrorAs(t, err, &quantile.ErrWrongPercent{})
+}
+
+func TestSketchMerge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	values := make([]float64, 3000)
+	for i := range values {
+		values[i] = math.Exp(rnd.NormFloat64()*2) * 1000
+	}
+
+	tests := []struct {
+		name      string
+		newSketch func() *quantile.Sketch
+	}{
+		{
+			name:      "exact",
+			newSketch: quantile.NewExact,
+		},
+		{
+			name:      "exact values exceeding capacity",
+			newSketch: func() *quantile.Sketch { return quantile.New(quantile.DefaultAccuracy, 1000) },
+		},
+		{
+			name:      "buckets",
+			newSketch: func() *quantile.Sketch { return quantile.New(quantile.DefaultAccuracy, 10) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.newSketch()
+			for _, value := range values {
+				want.Add(value)
+			}
+
+			// Части разного размера, чтобы одни хранили значения точно, а другие - в корзинах.
+			got := tt.newSketch()
+
+			for _, part := range [][]float64{values[:5], values[5:900], values[900:]} {
+				sketch := tt.newSketch()
+				for _, value := range part {
+					sketch.Add(value)
+				}
+
+				got.Merge(sketch)
+			}
+
+			assert.Equal(t, want.Count(), got.Count())
+			assert.Equal(t, want.Min(), got.Min())
+			assert.Equal(t, want.Max(), got.Max())
+			assert.InDelta(t, want.Sum(), got.Sum(), want.Sum()*1e-12)
+
+			for _, percent := range []float64{1, 50, 90, 95, 99, 99.9, 100} {
+				wantPercentile, err := want.Percentile(percent)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				gotPercentile, err := got.Percentile(percent)
+
+				assert.NoError(t, err)
+				assert.Equal(t, wantPercentile, gotPercentile, "percentile %g", percent)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"sort"
+	"time"
 )
 
 // DataWithCount хранит пару значений Data и Count.
@@ -11,13 +12,76 @@ type DataWithCount[T string | int] struct {
 	Count int
 }
 
+// Виды ошибок разбора некорректных строк лога.
+const (
+	MalformedFormat        = "format"          // Строка не соответствует формату лога.
+	MalformedRequest       = "request"         // Запрос не является http-запросом.
+	MalformedTime          = "time"            // Время или длительность не соответствует формату времени лога.
+	MalformedStatus        = "status"          // Код ответа не является числом.
+	MalformedBodyBytesSent = "body_bytes_sent" // Размер ответа не является числом.
+	MalformedOther         = "other"           // Прочие ошибки разбора.
+)
+
+// MalformedLines хранит количество некорректных строк файла, ошибка разбора которых имеет вид Kind.
+type MalformedLines struct {
+	File  string
+	Kind  string
+	Count int
+}
+
+// FileFormat хранит путь к файлу и определённый для него формат лога.
+type FileFormat struct {
+	File   string
+	Format string
+}
+
+// LatencyPercentiles - ранги перцентилей времени обработки запросов, вычисляемых для отчёта.
+var LatencyPercentiles = []float64{50, 90, 95, 99, 99.9}
+
+// Latency хранит статистику времени обработки запросов в секундах.
+type Latency struct {
+	Count       int // Количество запросов, время обработки которых известно.
+	Min         float64
+	Average     float64
+	Max         float64
+	Percentiles []float64 // Значения перцентилей в порядке LatencyPercentiles.
+}
+
+// ResourceLatency хранит статистику времени обработки запросов к ресурсу Resource.
+type ResourceLatency struct {
+	Resource string
+	Latency  Latency
+}
+
+// StatusClasses - классы кодов ответа, количество запросов которых считается в интервалах времени.
+var StatusClasses = []string{"1xx", "2xx", "3xx", "4xx", "5xx"}
+
+// StatusClass возвращает индекс класса кода ответа status в StatusClasses.
+// Для кодов вне [100, 600) возвращает false.
+func StatusClass(status int) (int, bool) {
+	if status < 100 || status >= 600 {
+		return 0, false
+	}
+
+	return status/100 - 1, true
+}
+
+// Bucket хранит статистику запросов, время которых лежит в интервале длительностью Report.BucketSize,
+// начинающемся в Start.
+type Bucket struct {
+	Start         time.Time
+	RequestsCount int
+	StatusClasses []int   // Количество запросов по классам кодов ответа в порядке StatusClasses.
+	BytesSent     int     // Суммарный размер ответов.
+	Latency       Latency // Время обработки запросов, если оно известно хотя бы для одного запроса интервала.
+}
+
 // Report - структура отчёта, содержащая результаты анализа и метаинформацию о нём.
 type Report struct {
 	Files                    []string
 	From                     string
 	To                       string
-	Field                    string
-	Value                    string
+	Filter                   string // Выражение фильтра записей.
 	RequestsCount            int
 	MostFrequentResources    []DataWithCount[string]
 	MostFrequentCodes        []DataWithCount[int]
@@ -25,12 +89,19 @@ type Report struct {
 	MostFrequentAgents       []DataWithCount[string]
 	AverageResponseSize      float64
 	Percentile95ResponseSize float64
+	FileFormats              []FileFormat      // Форматы файлов, определённые по их первым строкам.
+	UnrecognizedFiles        []string          // Файлы, формат которых не удалось определить.
+	MalformedLines           []MalformedLines  // Количество некорректных строк по файлам и видам ошибок.
+	Latency                  Latency           // Время обработки запросов, если оно известно хотя бы для одного запроса.
+	ResourceLatencies        []ResourceLatency // Время обработки запросов к ресурсам в порядке MostFrequentResources.
+	BucketSize               time.Duration     // Длительность интервалов времени Buckets, если они заданы.
+	Buckets                  []Bucket          // Статистика непустых интервалов времени в порядке их начала.
 }
 
 // New возвращает инициализированный Report.
 func New(
 	files []string,
-	from, to, field, value string,
+	from, to, filter string,
 	requestCount int,
 	resources map[string]int,
 	codes map[int]int,
@@ -79,8 +150,7 @@ func New(
 		Files:                    files,
 		From:                     from,
 		To:                       to,
-		Field:                    field,
-		Value:                    value,
+		Filter:                   filter,
 		RequestsCount:            requestCount,
 		MostFrequentResources:    rs,
 		MostFrequentCodes:        cd,
@@ -0,0 +1,21 @@
+package timespec
+
+import "fmt"
+
+// ErrWrongTime - ошибка значения, не являющегося ни одним из поддерживаемых представлений времени.
+type ErrWrongTime struct {
+	data string
+}
+
+func (e ErrWrongTime) Error() string {
+	return fmt.Sprintf("%s is not a valid time", e.data)
+}
+
+// ErrOffsetOverflow - ошибка смещения от текущего момента, не представимого длительностью time.Duration.
+type ErrOffsetOverflow struct {
+	data string
+}
+
+func (e ErrOffsetOverflow) Error() string {
+	return fmt.Sprintf("offset %s is too large", e.data)
+}
@@ -0,0 +1,174 @@
+package timespec
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zonedLayouts - форматы времени с указанием часового пояса.
+var zonedLayouts = []string{
+	time.RFC3339,
+	"02/Jan/2006:15:04:05 -0700", // Формат $time_local nginx.
+}
+
+// localLayouts - форматы времени без часового пояса, которые интерпретируются в часовом поясе, переданном в Parse.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"02/Jan/2006:15:04:05",
+	"2006-01-02",
+}
+
+// clockLayouts - форматы времени суток, уточняющего день в today, yesterday и tomorrow.
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// dayOffsets сопоставляет названиям дней их смещение в днях от текущего.
+var dayOffsets = map[string]int{
+	"today":     0,
+	"yesterday": -1,
+	"tomorrow":  1,
+}
+
+// units сопоставляет единицам измерения длительностей их величину.
+var units = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+var (
+	relativeRegExp  = regexp.MustCompile(`^(?:now\s*)?([+-])\s*((?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h|d|w))+)$`)
+	componentRegExp = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w)`)
+	dayRegExp       = regexp.MustCompile(`^(today|yesterday|tomorrow)(?:\s+(\S+))?$`)
+)
+
+// Parse парсит момент времени value относительно текущего момента now и возвращает его в часовом поясе loc.
+//
+// Поддерживаются:
+//   - now - текущий момент;
+//   - смещение от текущего момента со знаком, например -24h, +30m, -1d12h или now-1w, с единицами
+//     ns, us, µs, ms, s, m, h, d (сутки) и w (неделя);
+//   - today, yesterday и tomorrow с необязательным временем суток, например "yesterday 00:00" или "today 13:30:15";
+//   - время в формате RFC 3339 или $time_local nginx, например 07/Nov/2024:16:07:55 +0000;
+//   - время без часового пояса, например 2024-11-07 16:07, 2024-11-07T16:07:55 или 07/Nov/2024:16:07:55,
+//     и дата без времени, например 2024-11-07, интерпретируемые в часовом поясе loc.
+func Parse(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	now = now.In(loc)
+
+	if value == "now" {
+		return now, nil
+	}
+
+	offset, ok, err := parseOffset(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if ok {
+		return now.Add(offset), nil
+	}
+
+	if moment, ok := parseDay(value, now); ok {
+		return moment, nil
+	}
+
+	for _, layout := range zonedLayouts {
+		if moment, err := time.Parse(layout, value); err == nil {
+			return moment.In(loc), nil
+		}
+	}
+
+	for _, layout := range localLayouts {
+		if moment, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return moment, nil
+		}
+	}
+
+	return time.Time{}, ErrWrongTime{value}
+}
+
+// parseOffset парсит смещение от текущего момента со знаком.
+// Если value не является смещением, в качестве второго значения возвращает false.
+// Если смещение не представимо длительностью time.Duration, возвращает ErrOffsetOverflow.
+func parseOffset(value string) (time.Duration, bool, error) {
+	match := relativeRegExp.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false, nil
+	}
+
+	var offset time.Duration
+
+	for _, component := range componentRegExp.FindAllStringSubmatch(match[2], -1) {
+		number, err := strconv.ParseFloat(component[1], 64)
+		if err != nil {
+			return 0, false, nil
+		}
+
+		unit := units[component[2]]
+		if number >= float64(math.MaxInt64/unit) {
+			return 0, true, ErrOffsetOverflow{value}
+		}
+
+		part := time.Duration(number * float64(unit))
+		if offset > math.MaxInt64-part {
+			return 0, true, ErrOffsetOverflow{value}
+		}
+
+		offset += part
+	}
+
+	if match[1] == "-" {
+		offset = -offset
+	}
+
+	return offset, true, nil
+}
+
+// parseDay парсит название дня относительно текущего момента now с необязательным временем суток.
+// Без времени суток возвращает начало дня.
+func parseDay(value string, now time.Time) (time.Time, bool) {
+	match := dayRegExp.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	var clock time.Time
+
+	if match[2] != "" {
+		var ok bool
+
+		clock, ok = parseClock(match[2])
+		if !ok {
+			return time.Time{}, false
+		}
+	}
+
+	year, month, day := now.Date()
+
+	return time.Date(
+		year, month, day+dayOffsets[match[1]], clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location(),
+	), true
+}
+
+// parseClock парсит время суток.
+func parseClock(value string) (time.Time, bool) {
+	for _, layout := range clockLayouts {
+		if clock, err := time.Parse(layout, value); err == nil {
+			return clock, true
+		}
+	}
+
+	return time.Time{}, false
+}
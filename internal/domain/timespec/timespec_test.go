@@ -0,0 +1,137 @@
+package timespec_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/domain/timespec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2024, time.November, 8, 14, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "now",
+			value: "now",
+			loc:   time.UTC,
+			want:  now,
+		},
+		{
+			name:  "negative offset",
+			value: "-24h",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.November, 7, 14, 30, 15, 0, time.UTC),
+		},
+		{
+			name:  "offset with days and weeks",
+			value: "now - 1w2d1.5h",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.October, 30, 13, 0, 15, 0, time.UTC),
+		},
+		{
+			name:  "positive offset",
+			value: "+30m",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.November, 8, 15, 0, 15, 0, time.UTC),
+		},
+		{
+			name:  "yesterday with clock in time zone",
+			value: "yesterday 00:00",
+			loc:   moscow,
+			want:  time.Date(2024, time.November, 7, 0, 0, 0, 0, moscow),
+		},
+		{
+			name:  "today",
+			value: "today",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.November, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "tomorrow with seconds",
+			value: "tomorrow 13:30:15",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.November, 9, 13, 30, 15, 0, time.UTC),
+		},
+		{
+			name:  "rfc3339 converted to time zone",
+			value: "2024-11-07T16:07:56Z",
+			loc:   moscow,
+			want:  time.Date(2024, time.November, 7, 19, 7, 56, 0, moscow),
+		},
+		{
+			name:  "nginx",
+			value: "07/Nov/2024:16:07:56 +0300",
+			loc:   time.UTC,
+			want:  time.Date(2024, time.November, 7, 13, 7, 56, 0, time.UTC),
+		},
+		{
+			name:  "local time",
+			value: "2024-11-07 16:07",
+			loc:   moscow,
+			want:  time.Date(2024, time.November, 7, 16, 7, 0, 0, moscow),
+		},
+		{
+			name:  "date only",
+			value: "2024-11-07",
+			loc:   moscow,
+			want:  time.Date(2024, time.November, 7, 0, 0, 0, 0, moscow),
+		},
+		{
+			name:    "offset without sign",
+			value:   "24h",
+			loc:     time.UTC,
+			wantErr: true,
+		},
+		{
+			name:    "wrong clock",
+			value:   "yesterday noon",
+			loc:     time.UTC,
+			wantErr: true,
+		},
+		{
+			name:    "wrong date",
+			value:   "2024-13-07",
+			loc:     time.UTC,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timespec.Parse(tt.value, now, tt.loc)
+			if tt.wantErr {
+				assert.ErrorAs(t, err, &timespec.ErrWrongTime{})
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+			assert.Equal(t, tt.loc, got.Location())
+		})
+	}
+}
+
+func TestParseOffsetOverflow(t *testing.T) {
+	now := time.Date(2024, time.November, 8, 14, 39, 44, 0, time.UTC)
+
+	for _, value := range []string{"-100000000w", "+100000d100000d", "now-9999999999999h"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := timespec.Parse(value, now, time.UTC)
+
+			assert.ErrorAs(t, err, &timespec.ErrOffsetOverflow{})
+		})
+	}
+}
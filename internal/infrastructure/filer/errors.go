@@ -10,3 +10,12 @@ type ErrUnknownFormat struct {
 func (e ErrUnknownFormat) Error() string {
 	return fmt.Sprintf("unknown format (%s) for writing", e.format)
 }
+
+// ErrStdoutFormat - ошибка формата, который нельзя записать в стандартный вывод.
+type ErrStdoutFormat struct {
+	format string
+}
+
+func (e ErrStdoutFormat) Error() string {
+	return fmt.Sprintf("format (%s) can`t be written to stdout", e.format)
+}
@@ -1,16 +1,55 @@
 package filer
 
 import (
+	"archive/zip"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-const relativePath = "/internal/infrastructure/reports/" // Относительный путь от проекта к директории, куда необходимо сохранить файл.
+const (
+	// Stdout - значение Filer.Output, при котором размеченный отчёт записывается в стандартный вывод.
+	Stdout = "-"
+	// DefaultOutput - текущая директория, в которую по умолчанию сохраняется отчёт с именем по умолчанию,
+	// например report.md.
+	DefaultOutput = "."
+	dateLayout    = "2006-01-02"      // Формат шаблона {date}.
+	timeLayout    = "150405"          // Формат шаблона {time}.
+	boundLayout   = "20060102T150405" // Формат шаблонов {from} и {to}, допустимый в именах файлов.
+	noFrom        = "start"           // Значение шаблона {from}, если начальная граница времени не задана.
+	noTo          = "end"             // Значение шаблона {to}, если конечная граница времени не задана.
+	// maxTempAttempts - количество попыток выбрать имя временного файла, не совпадающее с существующим.
+	maxTempAttempts = 10000
+)
+
+// archiveTime - время изменения файлов архива таблиц, минимальное для формата zip.
+// Оно не зависит от времени сохранения, поэтому архивы одинаковых таблиц совпадают.
+var archiveTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 // Filer умеет сохранять файл с размеченным отчётом.
-type Filer struct{}
+type Filer struct {
+	// Output - Stdout, путь к файлу или путь к директории, в которую сохраняется файл с именем по умолчанию.
+	// Путь считается директорией, если оканчивается разделителем или указывает на существующую директорию.
+	// Путь может содержать шаблоны {format}, {from}, {to}, {date} и {time}, заменяемые форматом отчёта,
+	// границами времени отчёта, датой и временем сохранения.
+	// Если Output пуст, отчёт сохраняется в DefaultOutput.
+	Output string
+	From   time.Time        // Начальная граница времени отчёта или нулевое время, если она не задана.
+	To     time.Time        // Конечная граница времени отчёта или нулевое время, если она не задана.
+	Now    func() time.Time // Возвращает время сохранения. Если nil, используется time.Now.
+}
 
 // File сохраняет файл соответствующего расширения с записанным в него размеченным отчётом, возвращая указатель на него.
+// Файлы записываются во временный файл и переименовываются, поэтому прежний отчёт заменяется атомарно,
+// а textfile collector node_exporter никогда не прочитает частично записанные метрики формата openmetrics.
+// Если Output равен Stdout, отчёт записывается в стандартный вывод, и возвращается os.Stdout.
 func (w *Filer) File(markup, format string) (*os.File, error) {
 	var name string
 
@@ -19,27 +58,257 @@ func (w *Filer) File(markup, format string) (*os.File, error) {
 		name = "report.md"
 	case "adoc":
 		name = "report.adoc"
+	case "html":
+		name = "report.html"
+	case "json":
+		name = "report.json"
+	case "openmetrics":
+		// textfile collector читает только файлы с расширением .prom.
+		name = "report.prom"
+	case "text":
+		name = "report.txt"
+	default:
+		return nil, ErrUnknownFormat{format}
+	}
+
+	write := func(dst io.Writer) error {
+		_, err := io.WriteString(dst, markup)
+
+		return err
+	}
+
+	return w.save(format, name, write)
+}
+
+// Tables сохраняет файлы таблиц отчёта tables, содержимое которых указано по их именам, возвращая указатель
+// на директорию или архив. Для форматов csv и tsv файлы сохраняются в директорию, а для форматов csv-zip и tsv-zip -
+// в zip-архив в порядке имён. Файлы и архив, как и в File, заменяются атомарно.
+// Если Output равен Stdout, архив записывается в стандартный вывод, и возвращается os.Stdout.
+func (w *Filer) Tables(tables map[string][]byte, format string) (*os.File, error) {
+	switch format {
+	case "csv", "tsv":
+		if w.Output == Stdout {
+			return nil, ErrStdoutFormat{format}
+		}
+
+		path, err := w.path(format, "report")
+		if err != nil {
+			return nil, err
+		}
+
+		return writeDir(tables, path)
+	case "csv-zip", "tsv-zip":
+		return w.save(format, "report.zip", func(dst io.Writer) error {
+			return writeArchive(dst, tables)
+		})
 	default:
 		return nil, ErrUnknownFormat{format}
 	}
+}
+
+// save записывает с помощью write файл отчёта формата format с именем по умолчанию name и возвращает указатель на него.
+func (w *Filer) save(format, name string, write func(dst io.Writer) error) (*os.File, error) {
+	if w.Output == Stdout {
+		err := write(os.Stdout)
+		if err != nil {
+			return nil, fmt.Errorf("can`t write to stdout: %w", err)
+		}
 
-	path, err := os.Getwd()
+		return os.Stdout, nil
+	}
+
+	path, err := w.path(format, name)
 	if err != nil {
-		return nil, fmt.Errorf("can`t get current working directory: %w", err)
+		return nil, err
 	}
 
-	err = os.Chdir(path + relativePath)
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
 	if err != nil {
-		return nil, fmt.Errorf("can`t change working directory: %w", err)
+		return nil, fmt.Errorf("can`t create directory: %w", err)
 	}
 
-	file, err := os.Create(name)
+	err = writeAtomically(path, write)
 	if err != nil {
-		return nil, fmt.Errorf("can`t create file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	fmt.Fprint(file, markup)
+	return open(path)
+}
+
+// path возвращает путь к файлу отчёта формата format: Output с заменёнными шаблонами или,
+// если Output является директорией, путь к файлу name в ней.
+func (w *Filer) path(format, name string) (string, error) {
+	output := w.Output
+	if output == "" {
+		output = DefaultOutput
+	}
+
+	now := time.Now
+	if w.Now != nil {
+		now = w.Now
+	}
+
+	from, to := noFrom, noTo
+
+	if !w.From.IsZero() {
+		from = w.From.Format(boundLayout)
+	}
+
+	if !w.To.IsZero() {
+		to = w.To.Format(boundLayout)
+	}
+
+	saved := now()
+
+	path := strings.NewReplacer(
+		"{format}", format,
+		"{from}", from,
+		"{to}", to,
+		"{date}", saved.Format(dateLayout),
+		"{time}", saved.Format(timeLayout),
+	).Replace(output)
+
+	if strings.HasSuffix(path, string(filepath.Separator)) || strings.HasSuffix(path, "/") {
+		return filepath.Join(path, name), nil
+	}
+
+	info, err := os.Stat(path)
+
+	switch {
+	case err == nil && info.IsDir():
+		return filepath.Join(path, name), nil
+	case err == nil || errors.Is(err, os.ErrNotExist):
+		return path, nil
+	default:
+		return "", fmt.Errorf("can`t get info about output %s: %w", path, err)
+	}
+}
+
+// writeAtomically записывает с помощью write временный файл директории файла name и переименовывает его в name.
+// Переименование заменяет прежний файл атомарно.
+func writeAtomically(name string, write func(dst io.Writer) error) error {
+	dir, base := filepath.Split(name)
+
+	tmp, err := createTemp(dir, base)
+	if err != nil {
+		return fmt.Errorf("can`t create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // После успешного переименования файла уже нет, и ошибка игнорируется.
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	err = errors.Join(err, tmp.Close())
+	if err != nil {
+		return fmt.Errorf("can`t write temporary file: %w", err)
+	}
+
+	// Заменяемый файл сохраняет свои права, а новый файл получает права, заданные при создании временного файла.
+	if info, err := os.Stat(name); err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm())
+		if err != nil {
+			return fmt.Errorf("can`t change file mode: %w", err)
+		}
+	}
+
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		return fmt.Errorf("can`t rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// createTemp создаёт в директории dir временный файл для файла base с правами 0666 без битов umask,
+// как у файлов, создаваемых os.Create, чтобы отчёт, например метрики, могли читать и другие процессы.
+// Имя временного файла не оканчивается расширением base, чтобы его не прочитал, например, textfile collector.
+func createTemp(dir, base string) (*os.File, error) {
+	for range maxTempAttempts {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+
+		return file, err
+	}
+
+	return nil, fmt.Errorf("can`t find unused name for %s: %w", base, os.ErrExist)
+}
+
+// writeDir атомарно сохраняет файлы tables в директорию dir, создавая её, и возвращает указатель на неё.
+func writeDir(tables map[string][]byte, dir string) (*os.File, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("can`t create directory: %w", err)
+	}
+
+	for _, name := range sortedNames(tables) {
+		content := tables[name]
+
+		err = writeAtomically(filepath.Join(dir, filepath.Base(name)), func(dst io.Writer) error {
+			_, err := dst.Write(content)
+
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can`t write %s: %w", name, err)
+		}
+	}
+
+	return open(dir)
+}
+
+// writeArchive записывает в dst zip-архив файлов tables в порядке их имён.
+func writeArchive(dst io.Writer, tables map[string][]byte) error {
+	archive := zip.NewWriter(dst)
+
+	for _, name := range sortedNames(tables) {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: archiveTime,
+		})
+		if err != nil {
+			return fmt.Errorf("can`t create archive file %s: %w", name, err)
+		}
+
+		_, err = file.Write(tables[name])
+		if err != nil {
+			return fmt.Errorf("can`t write archive file %s: %w", name, err)
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return fmt.Errorf("can`t close archive: %w", err)
+	}
+
+	return nil
+}
+
+// sortedNames возвращает имена файлов tables в порядке возрастания.
+func sortedNames(tables map[string][]byte) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// open возвращает указатель на закрытый после открытия файл или директорию path.
+func open(path string) (*os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can`t open %s: %w", path, err)
+	}
+	defer file.Close()
 
 	return file, nil
 }
@@ -0,0 +1,186 @@
+package filer_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/es-debug/backend-academy-2024-go-template/internal/infrastructure/filer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// now возвращает фиксированное время сохранения отчёта.
+func now() time.Time {
+	return time.Date(2024, time.November, 8, 14, 39, 44, 0, time.UTC)
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string // Путь относительно временной директории.
+		format string
+		from   time.Time
+		want   string // Путь к файлу отчёта относительно временной директории.
+	}{
+		{
+			name:   "file",
+			output: "out.md",
+			format: "markdown",
+			want:   "out.md",
+		},
+		{
+			name:   "new directory",
+			output: "reports/",
+			format: "adoc",
+			want:   "reports/report.adoc",
+		},
+		{
+			name:   "existing directory",
+			output: ".",
+			format: "json",
+			want:   "report.json",
+		},
+		{
+			name:   "templates",
+			output: "{date}/{format}-{from}-{to}-{time}.txt",
+			format: "text",
+			from:   time.Date(2024, time.November, 7, 16, 7, 56, 0, time.UTC),
+			want:   "2024-11-08/text-20241107T160756-end-143944.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			// Путь не очищается filepath.Join, чтобы сохранить разделитель в конце.
+			w := &filer.Filer{Output: dir + string(filepath.Separator) + tt.output, From: tt.from, Now: now}
+
+			file, err := w.File("markup", tt.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Join(dir, tt.want), file.Name())
+
+			content, err := os.ReadFile(filepath.Join(dir, tt.want))
+			require.NoError(t, err)
+			assert.Equal(t, "markup", string(content))
+		})
+	}
+}
+
+func TestFileDefaultOutput(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+
+	file, err := (&filer.Filer{}).File("markup", "markdown")
+	require.NoError(t, err)
+	assert.Equal(t, "report.md", file.Name())
+
+	// В текущей директории не создаются другие директории.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "report.md", entries[0].Name())
+}
+
+func TestFileReplace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.prom")
+
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	_, err := (&filer.Filer{Output: path}).File("new", "openmetrics")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	// Заменённый файл сохраняет свои права.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Временные файлы не остаются в директории.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileMode(t *testing.T) {
+	dir := t.TempDir()
+
+	// Права файла, созданного с правами 0666, учитывают umask так же, как права отчёта.
+	reference := filepath.Join(dir, "reference")
+	require.NoError(t, os.WriteFile(reference, nil, 0o666))
+
+	want, err := os.Stat(reference)
+	require.NoError(t, err)
+
+	file, err := (&filer.Filer{Output: filepath.Join(dir, "report.prom")}).File("metrics", "openmetrics")
+	require.NoError(t, err)
+
+	got, err := os.Stat(file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, want.Mode().Perm(), got.Mode().Perm())
+}
+
+func TestTables(t *testing.T) {
+	tables := map[string][]byte{"general.csv": []byte("general"), "codes.csv": []byte("codes")}
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		file, err := (&filer.Filer{Output: dir}).Tables(tables, "csv")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "report"), file.Name())
+
+		for name, want := range tables {
+			content, err := os.ReadFile(filepath.Join(dir, "report", name))
+			require.NoError(t, err)
+			assert.Equal(t, want, content)
+		}
+
+		_, err = (&filer.Filer{Output: filer.Stdout}).Tables(tables, "csv")
+		assert.ErrorAs(t, err, &filer.ErrStdoutFormat{})
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		dir := t.TempDir()
+
+		file, err := (&filer.Filer{Output: dir}).Tables(tables, "csv-zip")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "report.zip"), file.Name())
+
+		archive, err := zip.OpenReader(file.Name())
+		require.NoError(t, err)
+
+		defer archive.Close()
+
+		// Файлы архива упорядочены по имени.
+		names := make([]string, 0, len(archive.File))
+		for _, entry := range archive.File {
+			names = append(names, entry.Name)
+		}
+
+		assert.Equal(t, []string{"codes.csv", "general.csv"}, names)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := (&filer.Filer{Output: t.TempDir()}).Tables(tables, "markdown")
+		assert.ErrorAs(t, err, &filer.ErrUnknownFormat{})
+	})
+}
+
+func TestFileUnknownFormat(t *testing.T) {
+	_, err := (&filer.Filer{Output: t.TempDir()}).File("markup", "pdf")
+	assert.ErrorAs(t, err, &filer.ErrUnknownFormat{})
+}